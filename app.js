@@ -167,8 +167,10 @@ async function placeOrder() {
         
         console.log('Order params for WASM:', orderParams);
         
-        const signedTxJSON = await window.LighterWASM.signCreateOrder(orderParams);
+        const signed = await window.LighterWASM.signCreateOrder(orderParams);
+        const signedTxJSON = signed.txInfo;
         console.log('Signed TX JSON from WASM:', signedTxJSON);
+        console.log('TX hash:', signed.txHash);
         
         const signedTx = JSON.parse(signedTxJSON);
         console.log('Parsed signed TX:', signedTx);
@@ -179,10 +181,10 @@ async function placeOrder() {
         
         // API expects multipart/form-data, not JSON!
         const formData = new FormData();
-        formData.append('tx_type', '14'); // CREATE_ORDER = 14
+        formData.append('tx_type', signed.txType.toString());
         formData.append('tx_info', signedTxJSON);
         
-        console.log('  tx_type: 14 (CREATE_ORDER)');
+        console.log('  tx_type:', signed.txType);
         console.log('  tx_info (signed TX):', signedTxJSON);
         console.log('  Endpoint:', `${CONFIG.baseUrl}/api/v1/sendTx`);
         
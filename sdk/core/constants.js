@@ -1,4 +1,7 @@
 // Lighter SDK Constants
+import { WASM_ERROR_CODES, WASM_ERRORS } from './errors.js';
+
+export { WASM_ERROR_CODES };
 
 export const ENDPOINTS = {
     mainnet: {
@@ -147,8 +150,21 @@ export const POOL_STATUS = {
     ACTIVE: 1
 };
 
+// Codes in the body of rejected API responses
+export const API_ERROR_CODES = {
+    INVALID_NONCE: 21104
+};
+
+// Must match protocolVersion in wasm/info.go
+export const WASM_PROTOCOL_VERSION = 1;
+
+// SDK messages plus one entry per LighterWASM error code (generated in
+// errors.js from wasm/errcodes). Errors thrown by the signer carry the key
+// in their code property.
 export const ERRORS = {
+    ...WASM_ERRORS,
     WASM_NOT_LOADED: 'WASM not loaded',
+    WASM_PROTOCOL_MISMATCH: 'lighter.wasm protocol version mismatch',
     MISSING_PRIVATE_KEY: 'Private key required',
     MISSING_NONCE: 'Nonce required',
     INVALID_NETWORK: 'Invalid network',
@@ -0,0 +1,25 @@
+// Code generated by wasm/errcodes/gen.go; DO NOT EDIT.
+
+// Values of the code property of errors from LighterWASM
+export const WASM_ERROR_CODES = Object.freeze({
+    INVALID_KEY: 'INVALID_KEY',
+    INVALID_PARAM: 'INVALID_PARAM',
+    SIGN_FAILED: 'SIGN_FAILED',
+    MARSHAL_FAILED: 'MARSHAL_FAILED',
+    NONCE_NOT_SEEDED: 'NONCE_NOT_SEEDED',
+    NONCE_STALE: 'NONCE_STALE',
+    INTERNAL: 'INTERNAL',
+    PANIC: 'PANIC'
+});
+
+// Description of each code, merged into ERRORS
+export const WASM_ERRORS = {
+    INVALID_KEY: 'Invalid private key, keystore or session',
+    INVALID_PARAM: 'Invalid or missing parameter',
+    SIGN_FAILED: 'Failed to sign transaction',
+    MARSHAL_FAILED: 'Failed to encode signed transaction',
+    NONCE_NOT_SEEDED: 'Nonce manager not seeded for this account and API key',
+    NONCE_STALE: 'Nonce sequence out of date, resync from the server',
+    INTERNAL: 'Internal signer error',
+    PANIC: 'Signer panicked'
+};
@@ -461,7 +461,13 @@ export class LighterAPI {
 
     // === TRANSACTION SUBMISSION ===
     
+    // Accepts the object resolved by the LighterWASM sign* functions, or the
+    // legacy (txType, txInfo) pair
     async sendTx(txType, txInfo) {
+        if (typeof txType === 'object' && txType !== null) {
+            ({ txType, txInfo } = txType);
+        }
+
         const formData = new FormData();
         formData.append('tx_type', txType.toString());
         formData.append('tx_info', txInfo);
@@ -474,13 +480,24 @@ export class LighterAPI {
         const data = await response.json();
         
         if (!response.ok || (data.code && data.code !== 200)) {
-            throw new Error(data.message || `Transaction failed: ${JSON.stringify(data)}`);
+            throw new LighterAPIError(
+                data.message || `Transaction failed: ${JSON.stringify(data)}`,
+                '/api/v1/sendTx',
+                response.status,
+                data
+            );
         }
         
         return data;
     }
 
+    // Accepts an array of signed tx objects, or parallel txTypes / txInfos arrays
     async sendTxBatch(txTypes, txInfos) {
+        if (txInfos === undefined && Array.isArray(txTypes)) {
+            txInfos = txTypes.map(tx => tx.txInfo);
+            txTypes = txTypes.map(tx => tx.txType);
+        }
+
         const formData = new FormData();
         formData.append('tx_types', JSON.stringify(txTypes));
         formData.append('tx_infos', JSON.stringify(txInfos));
@@ -493,7 +510,12 @@ export class LighterAPI {
         const data = await response.json();
         
         if (!response.ok || (data.code && data.code !== 200)) {
-            throw new Error(data.message || `Batch transaction failed`);
+            throw new LighterAPIError(
+                data.message || `Batch transaction failed`,
+                '/api/v1/sendTxBatch',
+                response.status,
+                data
+            );
         }
         
         return data;
@@ -15,6 +15,9 @@ import {
     DECIMALS,
     DEFAULTS,
     POOL_STATUS,
+    WASM_PROTOCOL_VERSION,
+    WASM_ERROR_CODES,
+    API_ERROR_CODES,
     ERRORS
 } from './constants.js';
 
@@ -31,6 +34,8 @@ export class LighterSDK {
         // Store config
         this.config = {
             privateKey: config.privateKey,
+            keystore: config.keystore,
+            password: config.password,
             accountIndex: config.accountIndex,
             apiKeyIndex: config.apiKeyIndex !== undefined ? config.apiKeyIndex : 0,
             network: networkName,
@@ -45,10 +50,13 @@ export class LighterSDK {
         this.api = new LighterAPI(this.apiUrl);
         this.ws = null;
         this.wasmReady = false;
+        this.session = null;
         this.currentNonce = null;
+        this.nonceSeeded = false;
+        this.reservedNonces = new Set();
 
         // Expose helpers
-        this.constants = { TX_TYPES, ORDER_TYPES, TIME_IN_FORCE, MARGIN_MODES, MARGIN_DIRECTION, GROUPING_TYPES, CANCEL_ALL_TIF, ORDER_SIDES, MARKETS, DECIMALS, DEFAULTS, POOL_STATUS };
+        this.constants = { TX_TYPES, ORDER_TYPES, TIME_IN_FORCE, MARGIN_MODES, MARGIN_DIRECTION, GROUPING_TYPES, CANCEL_ALL_TIF, ORDER_SIDES, MARKETS, DECIMALS, DEFAULTS, POOL_STATUS, ERRORS, WASM_ERROR_CODES, API_ERROR_CODES };
     }
 
     // === INITIALIZATION ===
@@ -57,13 +65,55 @@ export class LighterSDK {
         console.log('[SDK] Initializing...');
         
         // Check if WASM is already loaded
-        if (window.LighterWASM && window.LighterWASM.ready) {
-            this.wasmReady = true;
-            console.log('[SDK] WASM already loaded');
-            return;
+        if (!window.LighterWASM) {
+            throw new Error('WASM not loaded. Load lighter.wasm and wasm_exec.js first.');
+        }
+        if (!window.LighterWASM.ready) {
+            // Loaded but failed its known-answer self-test
+            const report = window.LighterWASM.selfTest ? await window.LighterWASM.selfTest() : null;
+            const failed = report ? report.results.filter(r => !r.passed) : [];
+            if (failed.length > 0) {
+                throw new Error('WASM self-test failed: ' + failed.map(r => `${r.name}: ${r.error}`).join('; '));
+            }
+            throw new Error('WASM not loaded. Load lighter.wasm and wasm_exec.js first.');
+        }
+
+        // Builds from before info() speak protocol 0
+        this.wasmInfo = window.LighterWASM.info ? await window.LighterWASM.info() : { protocolVersion: 0 };
+        if (this.wasmInfo.protocolVersion !== WASM_PROTOCOL_VERSION) {
+            throw new Error(`${ERRORS.WASM_PROTOCOL_MISMATCH}: SDK expects ${WASM_PROTOCOL_VERSION}, lighter.wasm has ${this.wasmInfo.protocolVersion}. Rebuild lighter.wasm or update the SDK.`);
         }
 
-        throw new Error('WASM not loaded. Load lighter.wasm and wasm_exec.js first.');
+        this.wasmReady = true;
+        console.log('[SDK] WASM already loaded');
+
+        // Hand the key (or keystore and password) to the signer once and keep
+        // only the session handle
+        if ((this.config.privateKey || this.config.keystore) && !this.session) {
+            const key = this.config.keystore
+                ? { keystore: this.config.keystore, password: this.config.password }
+                : { privateKey: this.config.privateKey };
+
+            this.session = await window.LighterWASM.openSession({
+                ...key,
+                accountIndex: this.config.accountIndex,
+                apiKeyIndex: this.config.apiKeyIndex,
+                chainId: this.chainId
+            });
+            delete this.config.privateKey;
+            delete this.config.password;
+            console.log('[SDK] Signer session opened');
+        }
+    }
+
+    async close() {
+        this.disconnectWebSocket();
+
+        if (this.session) {
+            await window.LighterWASM.closeSession(this.session);
+            this.session = null;
+            console.log('[SDK] Signer session closed');
+        }
     }
 
     _ensureWASM() {
@@ -72,17 +122,113 @@ export class LighterSDK {
         }
     }
 
-    async _getNonce() {
+    // Reserve count consecutive nonces from the signer's nonce manager,
+    // seeding it from the server on first use
+    async _getNonce(count = 1) {
         if (!this.config.autoFetchNonce) {
             throw new Error('Auto nonce fetch disabled. Provide nonce manually.');
         }
 
+        if (!this.nonceSeeded) {
+            const data = await this.api.getNextNonce(this.config.accountIndex, this.config.apiKeyIndex);
+            await window.LighterWASM.seedNonce({ ...this._nonceKey(), nextNonce: data.nonce });
+            this.nonceSeeded = true;
+        }
+
+        const { nonce } = await window.LighterWASM.reserveNonce({ ...this._nonceKey(), count });
+        for (let i = 0; i < count; i++) {
+            this.reservedNonces.add(nonce + i);
+        }
+        this.currentNonce = nonce;
+        return nonce;
+    }
+
+    _nonceKey() {
+        if (this.session) {
+            return { session: this.session };
+        }
+        return { accountIndex: this.config.accountIndex, apiKeyIndex: this.config.apiKeyIndex };
+    }
+
+    // Mark a nonce reserved by _getNonce as used by an accepted tx
+    async commitNonce(nonce) {
+        if (!this.reservedNonces.delete(nonce)) {
+            return false;
+        }
+        return await window.LighterWASM.commitNonce({ ...this._nonceKey(), nonce });
+    }
+
+    // Hand a reserved nonce back after a failed sign or submit. If the
+    // exchange rejected the nonce itself, or a later nonce was already
+    // committed, resync from the server instead.
+    async rollbackNonce(nonce, error = null) {
+        if (!this.reservedNonces.has(nonce)) {
+            return false;
+        }
+
+        const data = error && error.responseData;
+        if (data && data.code === API_ERROR_CODES.INVALID_NONCE) {
+            await this.resyncNonce();
+            return true;
+        }
+
+        try {
+            const released = await window.LighterWASM.rollbackNonce({ ...this._nonceKey(), nonce });
+            for (const n of this.reservedNonces) {
+                if (n >= nonce) {
+                    this.reservedNonces.delete(n);
+                }
+            }
+            return released;
+        } catch (rollbackError) {
+            if (rollbackError.code !== WASM_ERROR_CODES.NONCE_STALE) {
+                throw rollbackError;
+            }
+            await this.resyncNonce();
+            return true;
+        }
+    }
+
+    // Reload nextNonce from the server and drop all pending reservations
+    async resyncNonce() {
         const data = await this.api.getNextNonce(this.config.accountIndex, this.config.apiKeyIndex);
+        await window.LighterWASM.resyncNonce({ ...this._nonceKey(), nextNonce: data.nonce });
+        this.reservedNonces.clear();
+        this.nonceSeeded = true;
         this.currentNonce = data.nonce;
-        return this.currentNonce;
+    }
+
+    // Call a WASM sign function, releasing the nonce if signing fails
+    async _sign(fn, params) {
+        try {
+            return await window.LighterWASM[fn](params);
+        } catch (error) {
+            await this.rollbackNonce(params.nonce, error);
+            throw error;
+        }
+    }
+
+    // Submit a signed tx and settle its nonce reservation
+    async _submit(signedTx) {
+        let result;
+        try {
+            result = await this.api.sendTx(signedTx);
+        } catch (error) {
+            await this.rollbackNonce(signedTx.nonce, error);
+            throw error;
+        }
+        await this.commitNonce(signedTx.nonce);
+        return result;
     }
 
     _getBaseParams(overrides = {}) {
+        if (this.session) {
+            return {
+                session: this.session,
+                ...overrides
+            };
+        }
+
         return {
             privateKey: this.config.privateKey,
             accountIndex: this.config.accountIndex,
@@ -114,7 +260,7 @@ export class LighterSDK {
         
         const orderParams = this._getBaseParams({
             nonce: nonce,
-            expiredAt: 0,
+            expiredAt: params.expiredAt || 0,
             marketIndex: marketIndex,
             clientOrderIndex: params.clientOrderIndex || Date.now(),
             baseAmount: baseAmount,
@@ -135,10 +281,10 @@ export class LighterSDK {
             orderExpiry: orderParams.orderExpiry,
             price: orderParams.price
         });
-        const signedTx = await window.LighterWASM.signCreateOrder(orderParams);
+        const signedTx = await this._sign('signCreateOrder', orderParams);
         
         console.log('[SDK] Submitting order to API');
-        const result = await this.api.sendTx(TX_TYPES.CREATE_ORDER, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -152,15 +298,16 @@ export class LighterSDK {
 
         const cancelParams = this._getBaseParams({
             nonce: nonce,
+            expiredAt: params.expiredAt,
             marketIndex: marketIndex,
             orderIndex: params.orderIndex
         });
 
         console.log('[SDK] Signing cancel:', cancelParams);
-        const signedTx = await window.LighterWASM.signCancelOrder(cancelParams);
+        const signedTx = await this._sign('signCancelOrder', cancelParams);
         
         console.log('[SDK] Submitting cancel to API');
-        const result = await this.api.sendTx(TX_TYPES.CANCEL_ORDER, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -174,6 +321,7 @@ export class LighterSDK {
 
         const modifyParams = this._getBaseParams({
             nonce: nonce,
+            expiredAt: params.expiredAt,
             marketIndex: marketIndex,
             orderIndex: params.orderIndex,
             baseAmount: Math.floor(params.amount * Math.pow(10, DECIMALS.BASE_AMOUNT)),
@@ -182,9 +330,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing modify:', modifyParams);
-        const signedTx = await window.LighterWASM.signModifyOrder(modifyParams);
+        const signedTx = await this._sign('signModifyOrder', modifyParams);
         
-        const result = await this.api.sendTx(TX_TYPES.MODIFY_ORDER, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -196,14 +344,15 @@ export class LighterSDK {
 
         const cancelAllParams = this._getBaseParams({
             nonce: nonce,
+            expiredAt: params.expiredAt,
             timeInForce: params.timeInForce || TIME_IN_FORCE.GOOD_TILL_TIME,
             time: params.time || Date.now()
         });
 
         console.log('[SDK] Signing cancel all');
-        const signedTx = await window.LighterWASM.signCancelAllOrders(cancelAllParams);
+        const signedTx = await this._sign('signCancelAllOrders', cancelAllParams);
         
-        const result = await this.api.sendTx(TX_TYPES.CANCEL_ALL_ORDERS, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -213,10 +362,12 @@ export class LighterSDK {
 
         const nonce = params.nonce !== undefined ? params.nonce : await this._getNonce();
 
-        // Convert orders
-        const convertedOrders = orders.map(order => ({
+        // Convert orders (client order indices must be integers; the signer
+        // rejects fractional values rather than truncating them)
+        const baseClientOrderIndex = Date.now();
+        const convertedOrders = orders.map((order, i) => ({
             marketIndex: typeof order.market === 'string' ? MARKETS[order.market] : order.market,
-            clientOrderIndex: order.clientOrderIndex || Date.now() + Math.random(),
+            clientOrderIndex: order.clientOrderIndex || baseClientOrderIndex + i,
             baseAmount: Math.floor(order.amount * Math.pow(10, DECIMALS.BASE_AMOUNT)),
             price: order.price ? Math.floor(order.price * Math.pow(10, DECIMALS.PRICE)) : 0,
             isAsk: order.side === 'sell' ? ORDER_SIDES.SELL : ORDER_SIDES.BUY,
@@ -229,14 +380,15 @@ export class LighterSDK {
 
         const batchParams = this._getBaseParams({
             nonce: nonce,
+            expiredAt: params.expiredAt,
             groupingType: params.groupingType || 0,
             orders: convertedOrders
         });
 
         console.log('[SDK] Signing batch orders');
-        const signedTx = await window.LighterWASM.signCreateGroupedOrders(batchParams);
+        const signedTx = await this._sign('signCreateGroupedOrders', batchParams);
         
-        const result = await this.api.sendTx(TX_TYPES.CREATE_GROUPED_ORDERS, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -260,9 +412,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing leverage update:', leverageParams);
-        const signedTx = await window.LighterWASM.signUpdateLeverage(leverageParams);
+        const signedTx = await this._sign('signUpdateLeverage', leverageParams);
         
-        const result = await this.api.sendTx(TX_TYPES.UPDATE_LEVERAGE, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -282,9 +434,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing margin update');
-        const signedTx = await window.LighterWASM.signUpdateMargin(marginParams);
+        const signedTx = await this._sign('signUpdateMargin', marginParams);
         
-        const result = await this.api.sendTx(TX_TYPES.UPDATE_MARGIN, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -301,9 +453,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing withdrawal');
-        const signedTx = await window.LighterWASM.signWithdraw(withdrawParams);
+        const signedTx = await this._sign('signWithdraw', withdrawParams);
         
-        const result = await this.api.sendTx(TX_TYPES.WITHDRAW, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -324,9 +476,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing transfer');
-        const signedTx = await window.LighterWASM.signTransfer(transferParams);
+        const signedTx = await this._sign('signTransfer', transferParams);
         
-        const result = await this.api.sendTx(TX_TYPES.TRANSFER, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -341,10 +493,47 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing sub-account creation');
-        const signedTx = await window.LighterWASM.signCreateSubAccount(subAccountParams);
-        
-        const result = await this.api.sendTx(TX_TYPES.CREATE_SUB_ACCOUNT, signedTx);
+        const signedTx = await this._sign('signCreateSubAccount', subAccountParams);
         
+        const result = await this._submit(signedTx);
+
+        return result;
+    }
+
+    // Register newPubKey for this account's apiKeyIndex. The account owner
+    // must also sign the returned l1Message: pass signMessage (a wallet
+    // personal_sign function), l1Signature, or l1PrivateKey.
+    async changeApiKey(newPubKey, l1 = {}) {
+        this._ensureWASM();
+
+        const nonce = await this._getNonce();
+
+        const changePubKeyParams = this._getBaseParams({
+            nonce: nonce,
+            newPubKey: newPubKey,
+            l1Signature: l1.l1Signature,
+            l1PrivateKey: l1.l1PrivateKey
+        });
+
+        console.log('[SDK] Signing pub key change');
+        const signedTx = await this._sign('signChangePubKey', changePubKeyParams);
+
+        if (l1.signMessage) {
+            try {
+                const l1Signature = await l1.signMessage(signedTx.l1Message);
+                const attached = await window.LighterWASM.attachL1Signature(signedTx.txInfo, {
+                    l1Signature,
+                    l1Address: l1.l1Address
+                });
+                signedTx.txInfo = attached.txInfo;
+            } catch (error) {
+                await this.rollbackNonce(nonce, error);
+                throw error;
+            }
+        }
+
+        const result = await this._submit(signedTx);
+
         return result;
     }
 
@@ -363,9 +552,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing public pool creation');
-        const signedTx = await window.LighterWASM.signCreatePublicPool(poolParams);
+        const signedTx = await this._sign('signCreatePublicPool', poolParams);
         
-        const result = await this.api.sendTx(TX_TYPES.CREATE_PUBLIC_POOL, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -384,9 +573,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing public pool update');
-        const signedTx = await window.LighterWASM.signUpdatePublicPool(updatePoolParams);
+        const signedTx = await this._sign('signUpdatePublicPool', updatePoolParams);
         
-        const result = await this.api.sendTx(TX_TYPES.UPDATE_PUBLIC_POOL, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -403,9 +592,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing mint shares');
-        const signedTx = await window.LighterWASM.signMintShares(mintParams);
+        const signedTx = await this._sign('signMintShares', mintParams);
         
-        const result = await this.api.sendTx(TX_TYPES.MINT_SHARES, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -422,9 +611,9 @@ export class LighterSDK {
         });
 
         console.log('[SDK] Signing burn shares');
-        const signedTx = await window.LighterWASM.signBurnShares(burnParams);
+        const signedTx = await this._sign('signBurnShares', burnParams);
         
-        const result = await this.api.sendTx(TX_TYPES.BURN_SHARES, signedTx);
+        const result = await this._submit(signedTx);
         
         return result;
     }
@@ -981,6 +1170,119 @@ export class LighterSDK {
         return await window.LighterWASM.generateKey();
     }
 
+    // Derive the API key for apiKeyIndex from a wallet. signMessage is any
+    // personal_sign function, e.g. (msg) => ethersSigner.signMessage(msg);
+    // the same wallet always yields the same key.
+    async deriveApiKeyFromWallet(signMessage, apiKeyIndex = this.config.apiKeyIndex) {
+        this._ensureWASM();
+
+        const signature = await signMessage(window.LighterWASM.keyDerivationMessage);
+        return await window.LighterWASM.deriveKeyFromSeed(signature, apiKeyIndex);
+    }
+
+    // Encrypt a private key into a keystore object for config.keystore
+    async encryptKey(privateKey, password, options = {}) {
+        this._ensureWASM();
+        return await window.LighterWASM.encryptKey(privateKey, password, options);
+    }
+
+    // Versions, revision and exports of the loaded lighter.wasm
+    async getWasmInfo() {
+        this._ensureWASM();
+        return await window.LighterWASM.info();
+    }
+
+    // Known-answer self-test the signer ran at startup
+    async selfTest() {
+        this._ensureWASM();
+        return await window.LighterWASM.selfTest();
+    }
+
+    // Public key of the configured API key
+    async getPublicKey() {
+        this._ensureWASM();
+        return await window.LighterWASM.getPublicKey(this.session ? { session: this.session } : this.config.privateKey);
+    }
+
+    // Check that the configured key matches the public key the exchange has
+    // registered for this account's apiKeyIndex
+    async verifyApiKey() {
+        this._ensureWASM();
+
+        const data = await this.api.getAccountApiKeys(this.config.accountIndex);
+        const entry = (data.api_keys || []).find(k => k.api_key_index === this.config.apiKeyIndex);
+        if (!entry) {
+            return { match: false, registered: false };
+        }
+
+        const key = this.session ? { session: this.session } : this.config.privateKey;
+        const result = await window.LighterWASM.comparePublicKey(key, entry.public_key);
+        return { ...result, registered: true, registeredPublicKey: entry.public_key };
+    }
+
+    // Sign any tx type without submitting it. txType is a TX_TYPES value or
+    // name; params are the raw WASM parameters for that type. A nonce reserved
+    // here stays pending until commitNonce or rollbackNonce.
+    async signTx(txType, params = {}) {
+        this._ensureWASM();
+
+        const nonce = params.nonce !== undefined ? params.nonce : await this._getNonce();
+        try {
+            return await window.LighterWASM.signTx(txType, this._getBaseParams({ ...params, nonce }));
+        } catch (error) {
+            await this.rollbackNonce(nonce, error);
+            throw error;
+        }
+    }
+
+    // Sign several txs with consecutive nonces without submitting them.
+    // txs is [{txType, params}]; the result's txTypes/txInfos go straight
+    // to this.api.sendTxBatch.
+    async signBatch(txs, options = {}) {
+        this._ensureWASM();
+
+        const startNonce = options.startNonce !== undefined ? options.startNonce : await this._getNonce(txs.length);
+        try {
+            return await window.LighterWASM.signBatch(this._getBaseParams({
+                startNonce,
+                expiredAt: options.expiredAt,
+                txs
+            }));
+        } catch (error) {
+            await this.rollbackNonce(startNonce, error);
+            throw error;
+        }
+    }
+
+    // Sign and submit several txs in one sendTxBatch call
+    async sendBatch(txs, options = {}) {
+        const batch = await this.signBatch(txs, options);
+
+        let result;
+        try {
+            result = await this.api.sendTxBatch(batch.txTypes, batch.txInfos);
+        } catch (error) {
+            await this.rollbackNonce(batch.txs[0].nonce, error);
+            throw error;
+        }
+        for (const tx of batch.txs) {
+            await this.commitNonce(tx.nonce);
+        }
+        return result;
+    }
+
+    // Decode a signed tx_info blob (e.g. from logs) into a readable object
+    async decodeTx(txType, txInfo) {
+        this._ensureWASM();
+        return await window.LighterWASM.decodeTx(txType, txInfo, this.chainId);
+    }
+
+    // Verify a signed tx_info blob against an API public key
+    async verifyTx(txType, txInfo, publicKey) {
+        this._ensureWASM();
+        return await window.LighterWASM.verifyTx(txType, txInfo, publicKey, this.chainId);
+    }
+
     // Helper to format amounts
     formatAmount(amount, decimals = DECIMALS.BASE_AMOUNT) {
         return amount / Math.pow(10, decimals);
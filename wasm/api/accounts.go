@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Account is the summary returned for each sub-account of an L1 address.
+// Amounts are decimal strings as the API sends them.
+type Account struct {
+	AccountType             int    `json:"account_type"`
+	Index                   int64  `json:"index"`
+	L1Address               string `json:"l1_address"`
+	CancelAllTime           int64  `json:"cancel_all_time"`
+	TotalOrderCount         int64  `json:"total_order_count"`
+	TotalIsolatedOrderCount int64  `json:"total_isolated_order_count"`
+	PendingOrderCount       int64  `json:"pending_order_count"`
+	AvailableBalance        string `json:"available_balance"`
+	Status                  int    `json:"status"`
+	Collateral              string `json:"collateral"`
+}
+
+// DetailedAccount is an account with its positions
+type DetailedAccount struct {
+	Account
+	AccountIndex       int64      `json:"account_index"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	TotalAssetValue    string     `json:"total_asset_value"`
+	CrossAssetValue    string     `json:"cross_asset_value"`
+	TotalRealizedPnl   string     `json:"total_realized_pnl"`
+	TotalUnrealizedPnl string     `json:"total_unrealized_pnl"`
+	Positions          []Position `json:"positions"`
+}
+
+// Position is an account's position in one market
+type Position struct {
+	MarketId               uint8  `json:"market_id"`
+	Symbol                 string `json:"symbol"`
+	InitialMarginFraction  string `json:"initial_margin_fraction"`
+	OpenOrderCount         int64  `json:"open_order_count"`
+	PendingOrderCount      int64  `json:"pending_order_count"`
+	PositionTiedOrderCount int64  `json:"position_tied_order_count"`
+	// Sign is 1 for long and -1 for short
+	Sign                int    `json:"sign"`
+	Position            string `json:"position"`
+	AvgEntryPrice       string `json:"avg_entry_price"`
+	PositionValue       string `json:"position_value"`
+	UnrealizedPnl       string `json:"unrealized_pnl"`
+	RealizedPnl         string `json:"realized_pnl"`
+	LiquidationPrice    string `json:"liquidation_price"`
+	TotalFundingPaidOut string `json:"total_funding_paid_out"`
+	MarginMode          uint8  `json:"margin_mode"`
+	AllocatedMargin     string `json:"allocated_margin"`
+}
+
+// AccountsResponse is returned by GetAccount and GetAccountByAddress
+type AccountsResponse struct {
+	status
+	Total    int64             `json:"total"`
+	Accounts []DetailedAccount `json:"accounts"`
+}
+
+// SubAccountsResponse is returned by GetAccountsByL1Address. The first
+// sub-account is the main account.
+type SubAccountsResponse struct {
+	status
+	L1Address   string    `json:"l1_address"`
+	SubAccounts []Account `json:"sub_accounts"`
+}
+
+// NextNonceResponse is returned by GetNextNonce
+type NextNonceResponse struct {
+	status
+	Nonce int64 `json:"nonce"`
+}
+
+// GetAccount fetches an account by index
+func (c *Client) GetAccount(ctx context.Context, accountIndex int64) (*AccountsResponse, error) {
+	out := &AccountsResponse{}
+	query := url.Values{"by": {"index"}, "value": {strconv.FormatInt(accountIndex, 10)}}
+	if err := c.get(ctx, "account", query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAccountByAddress fetches the account of an L1 Ethereum address
+func (c *Client) GetAccountByAddress(ctx context.Context, l1Address string) (*AccountsResponse, error) {
+	out := &AccountsResponse{}
+	query := url.Values{"by": {"l1_address"}, "value": {l1Address}}
+	if err := c.get(ctx, "account", query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAccountsByL1Address lists every sub-account of an L1 address
+func (c *Client) GetAccountsByL1Address(ctx context.Context, l1Address string) (*SubAccountsResponse, error) {
+	out := &SubAccountsResponse{}
+	query := url.Values{"l1_address": {l1Address}}
+	if err := c.get(ctx, "accountsByL1Address", query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetNextNonce fetches the nonce the next tx of an API key must use
+func (c *Client) GetNextNonce(ctx context.Context, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	out := &NextNonceResponse{}
+	query := url.Values{
+		"account_index": {strconv.FormatInt(accountIndex, 10)},
+		"api_key_index": {strconv.Itoa(int(apiKeyIndex))},
+	}
+	if err := c.get(ctx, "nextNonce", query, out); err != nil {
+		return 0, err
+	}
+	return out.Nonce, nil
+}
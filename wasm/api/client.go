@@ -0,0 +1,157 @@
+// Package api is a REST client for the Lighter API. It covers the same
+// endpoints as sdk/core/lighter-api.js and reports failures with the same
+// information as LighterAPIError.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Base URLs and chain ids, mirroring ENDPOINTS in sdk/core/constants.js
+const (
+	MainnetURL     = "https://mainnet.zklighter.elliot.ai"
+	TestnetURL     = "https://testnet.zklighter.elliot.ai"
+	MainnetChainId = 304
+	TestnetChainId = 305
+)
+
+// Error is a failed request: a non-2xx status, a body whose code is not
+// 200, or a transport failure (Status 0)
+type Error struct {
+	Endpoint string
+	Status   int
+	Message  string
+	// Body is the raw response body, nil if none was read
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("lighter api %s: %s (status %d)", e.Endpoint, e.Message, e.Status)
+}
+
+// Client calls one Lighter API deployment. The zero HTTPClient means
+// http.DefaultClient.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for baseURL, e.g. MainnetURL
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// status is embedded in every response; Code is 200 on success
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func (s *status) result() *status {
+	return s
+}
+
+type response interface {
+	result() *status
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// get fetches /api/v1/<path>?<query> into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out response) error {
+	endpoint := "/api/v1/" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+endpoint, nil)
+	if err != nil {
+		return &Error{endpoint, 0, err.Error(), nil}
+	}
+	return c.do(req, endpoint, out)
+}
+
+// post submits form as multipart/form-data, like the FormData bodies of
+// lighter-api.js
+func (c *Client) post(ctx context.Context, path string, form url.Values, out response) error {
+	endpoint := "/api/v1/" + path
+	body, contentType, err := multipartBody(form)
+	if err != nil {
+		return &Error{endpoint, 0, err.Error(), nil}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+endpoint, body)
+	if err != nil {
+		return &Error{endpoint, 0, err.Error(), nil}
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.do(req, endpoint, out)
+}
+
+func (c *Client) do(req *http.Request, endpoint string, out response) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return &Error{endpoint, 0, err.Error(), nil}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &Error{endpoint, resp.StatusCode, err.Error(), nil}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return &Error{endpoint, resp.StatusCode, fmt.Sprintf("API Error: %d", resp.StatusCode), body}
+		}
+		return &Error{endpoint, resp.StatusCode, fmt.Sprintf("Invalid response: %v", err), body}
+	}
+
+	st := out.result()
+	if resp.StatusCode/100 != 2 || (st.Code != 0 && st.Code != http.StatusOK) {
+		message := st.Message
+		if message == "" {
+			message = fmt.Sprintf("API Error: %d", resp.StatusCode)
+		}
+		return &Error{endpoint, resp.StatusCode, message, body}
+	}
+	return nil
+}
+
+func multipartBody(form url.Values) (io.Reader, string, error) {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, name := range names {
+		for _, value := range form[name] {
+			if err := w.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
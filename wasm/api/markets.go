@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// OrderBook describes one market. Fees and minimums are decimal strings.
+type OrderBook struct {
+	Symbol                 string `json:"symbol"`
+	MarketId               uint8  `json:"market_id"`
+	Status                 string `json:"status"`
+	TakerFee               string `json:"taker_fee"`
+	MakerFee               string `json:"maker_fee"`
+	LiquidationFee         string `json:"liquidation_fee"`
+	MinBaseAmount          string `json:"min_base_amount"`
+	MinQuoteAmount         string `json:"min_quote_amount"`
+	SupportedSizeDecimals  int    `json:"supported_size_decimals"`
+	SupportedPriceDecimals int    `json:"supported_price_decimals"`
+	SupportedQuoteDecimals int    `json:"supported_quote_decimals"`
+}
+
+// OrderBookDetail adds precision, margin and daily statistics to OrderBook
+type OrderBookDetail struct {
+	OrderBook
+	SizeDecimals                 int     `json:"size_decimals"`
+	PriceDecimals                int     `json:"price_decimals"`
+	QuoteMultiplier              int64   `json:"quote_multiplier"`
+	DefaultInitialMarginFraction int     `json:"default_initial_margin_fraction"`
+	MinInitialMarginFraction     int     `json:"min_initial_margin_fraction"`
+	MaintenanceMarginFraction    int     `json:"maintenance_margin_fraction"`
+	CloseoutMarginFraction       int     `json:"closeout_margin_fraction"`
+	LastTradePrice               float64 `json:"last_trade_price"`
+	DailyTradesCount             int64   `json:"daily_trades_count"`
+	DailyBaseTokenVolume         float64 `json:"daily_base_token_volume"`
+	DailyQuoteTokenVolume        float64 `json:"daily_quote_token_volume"`
+	DailyPriceLow                float64 `json:"daily_price_low"`
+	DailyPriceHigh               float64 `json:"daily_price_high"`
+	DailyPriceChange             float64 `json:"daily_price_change"`
+	OpenInterest                 float64 `json:"open_interest"`
+}
+
+// OrderBooksResponse is returned by GetOrderBooks
+type OrderBooksResponse struct {
+	status
+	OrderBooks []OrderBook `json:"order_books"`
+}
+
+// OrderBookDetailsResponse is returned by GetOrderBookDetails
+type OrderBookDetailsResponse struct {
+	status
+	OrderBookDetails []OrderBookDetail `json:"order_book_details"`
+}
+
+// BookOrder is one resting order of an order book snapshot
+type BookOrder struct {
+	OrderIndex          int64  `json:"order_index"`
+	OrderId             string `json:"order_id"`
+	OwnerAccountIndex   int64  `json:"owner_account_index"`
+	InitialBaseAmount   string `json:"initial_base_amount"`
+	RemainingBaseAmount string `json:"remaining_base_amount"`
+	Price               string `json:"price"`
+	OrderExpiry         int64  `json:"order_expiry"`
+}
+
+// OrderBookOrdersResponse is returned by GetOrderBookOrders
+type OrderBookOrdersResponse struct {
+	status
+	TotalAsks int64       `json:"total_asks"`
+	Asks      []BookOrder `json:"asks"`
+	TotalBids int64       `json:"total_bids"`
+	Bids      []BookOrder `json:"bids"`
+}
+
+// Trade is one fill
+type Trade struct {
+	TradeId      int64  `json:"trade_id"`
+	TxHash       string `json:"tx_hash"`
+	Type         string `json:"type"`
+	MarketId     uint8  `json:"market_id"`
+	Size         string `json:"size"`
+	Price        string `json:"price"`
+	UsdAmount    string `json:"usd_amount"`
+	AskId        int64  `json:"ask_id"`
+	BidId        int64  `json:"bid_id"`
+	AskAccountId int64  `json:"ask_account_id"`
+	BidAccountId int64  `json:"bid_account_id"`
+	IsMakerAsk   bool   `json:"is_maker_ask"`
+	BlockHeight  int64  `json:"block_height"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// TradesResponse is returned by GetTrades and GetRecentTrades
+type TradesResponse struct {
+	status
+	NextCursor string  `json:"next_cursor"`
+	Trades     []Trade `json:"trades"`
+}
+
+// Candlestick is one OHLCV bar. Volume0 is in base and Volume1 in quote.
+type Candlestick struct {
+	Timestamp   int64   `json:"timestamp"`
+	Open        float64 `json:"open"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Close       float64 `json:"close"`
+	Volume0     float64 `json:"volume0"`
+	Volume1     float64 `json:"volume1"`
+	LastTradeId int64   `json:"last_trade_id"`
+}
+
+// CandlesticksResponse is returned by GetCandlesticks
+type CandlesticksResponse struct {
+	status
+	Resolution   string        `json:"resolution"`
+	Candlesticks []Candlestick `json:"candlesticks"`
+}
+
+// FundingRate is the current rate of one market on one exchange
+type FundingRate struct {
+	MarketId uint8   `json:"market_id"`
+	Exchange string  `json:"exchange"`
+	Symbol   string  `json:"symbol"`
+	Rate     float64 `json:"rate"`
+}
+
+// FundingRatesResponse is returned by GetFundingRates
+type FundingRatesResponse struct {
+	status
+	FundingRates []FundingRate `json:"funding_rates"`
+}
+
+// GetOrderBooks lists every market
+func (c *Client) GetOrderBooks(ctx context.Context) (*OrderBooksResponse, error) {
+	out := &OrderBooksResponse{}
+	if err := c.get(ctx, "orderBooks", nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOrderBookDetails fetches the details of one market
+func (c *Client) GetOrderBookDetails(ctx context.Context, marketId uint8) (*OrderBookDetailsResponse, error) {
+	out := &OrderBookDetailsResponse{}
+	if err := c.get(ctx, "orderBookDetails", marketQuery(marketId, 0), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOrderBookOrders fetches up to limit resting orders per side of a market
+func (c *Client) GetOrderBookOrders(ctx context.Context, marketId uint8, limit int) (*OrderBookOrdersResponse, error) {
+	out := &OrderBookOrdersResponse{}
+	if err := c.get(ctx, "orderBookOrders", marketQuery(marketId, limit), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTrades fetches up to limit trades of a market
+func (c *Client) GetTrades(ctx context.Context, marketId uint8, limit int) (*TradesResponse, error) {
+	out := &TradesResponse{}
+	if err := c.get(ctx, "trades", marketQuery(marketId, limit), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRecentTrades fetches the latest limit trades of a market
+func (c *Client) GetRecentTrades(ctx context.Context, marketId uint8, limit int) (*TradesResponse, error) {
+	out := &TradesResponse{}
+	if err := c.get(ctx, "recentTrades", marketQuery(marketId, limit), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCandlesticks fetches up to limit bars of a market, interval being
+// e.g. "1m" or "1h"
+func (c *Client) GetCandlesticks(ctx context.Context, marketId uint8, interval string, limit int) (*CandlesticksResponse, error) {
+	out := &CandlesticksResponse{}
+	query := marketQuery(marketId, limit)
+	query.Set("interval", interval)
+	if err := c.get(ctx, "candlesticks", query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetFundingRates fetches the current funding rates of every market
+func (c *Client) GetFundingRates(ctx context.Context) (*FundingRatesResponse, error) {
+	out := &FundingRatesResponse{}
+	if err := c.get(ctx, "funding-rates", nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// marketQuery builds market_id[&limit]; a limit of 0 is left out
+func marketQuery(marketId uint8, limit int) url.Values {
+	query := url.Values{"market_id": {strconv.Itoa(int(marketId))}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
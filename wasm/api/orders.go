@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// AllMarkets disables the market filter of GetOrders
+const AllMarkets = -1
+
+// Order is an order as the API reports it. Amounts and prices are
+// decimal strings.
+type Order struct {
+	OrderIndex          int64  `json:"order_index"`
+	ClientOrderIndex    int64  `json:"client_order_index"`
+	OrderId             string `json:"order_id"`
+	ClientOrderId       string `json:"client_order_id"`
+	MarketIndex         uint8  `json:"market_index"`
+	OwnerAccountIndex   int64  `json:"owner_account_index"`
+	InitialBaseAmount   string `json:"initial_base_amount"`
+	Price               string `json:"price"`
+	Nonce               int64  `json:"nonce"`
+	RemainingBaseAmount string `json:"remaining_base_amount"`
+	IsAsk               bool   `json:"is_ask"`
+	BaseSize            int64  `json:"base_size"`
+	BasePrice           int64  `json:"base_price"`
+	FilledBaseAmount    string `json:"filled_base_amount"`
+	FilledQuoteAmount   string `json:"filled_quote_amount"`
+	Side                string `json:"side"`
+	// Type is e.g. "limit" or "stop-loss-limit"
+	Type              string `json:"type"`
+	TimeInForce       string `json:"time_in_force"`
+	ReduceOnly        bool   `json:"reduce_only"`
+	TriggerPrice      string `json:"trigger_price"`
+	OrderExpiry       int64  `json:"order_expiry"`
+	Status            string `json:"status"`
+	TriggerStatus     string `json:"trigger_status"`
+	TriggerTime       int64  `json:"trigger_time"`
+	ParentOrderIndex  int64  `json:"parent_order_index"`
+	ParentOrderId     string `json:"parent_order_id"`
+	ToTriggerOrderId0 string `json:"to_trigger_order_id_0"`
+	ToTriggerOrderId1 string `json:"to_trigger_order_id_1"`
+	ToCancelOrderId0  string `json:"to_cancel_order_id_0"`
+	BlockHeight       int64  `json:"block_height"`
+	Timestamp         int64  `json:"timestamp"`
+}
+
+// OrdersResponse is returned by GetOrders
+type OrdersResponse struct {
+	status
+	NextCursor string  `json:"next_cursor"`
+	Orders     []Order `json:"orders"`
+}
+
+// GetOrders lists the active or, with active false, inactive orders of an
+// account, optionally restricted to one market; pass AllMarkets for all
+func (c *Client) GetOrders(ctx context.Context, accountIndex int64, marketId int, active bool) (*OrdersResponse, error) {
+	endpoint := "accountInactiveOrders"
+	if active {
+		endpoint = "accountActiveOrders"
+	}
+
+	query := url.Values{"account_index": {strconv.FormatInt(accountIndex, 10)}}
+	if marketId != AllMarkets {
+		query.Set("market_id", strconv.Itoa(marketId))
+	}
+
+	out := &OrdersResponse{}
+	if err := c.get(ctx, endpoint, query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// Block is one L2 block
+type Block struct {
+	Commitment      string `json:"commitment"`
+	Height          int64  `json:"height"`
+	StateRoot       string `json:"state_root"`
+	CommittedTxHash string `json:"committed_tx_hash"`
+	CommittedAt     int64  `json:"committed_at"`
+	VerifiedTxHash  string `json:"verified_tx_hash"`
+	VerifiedAt      int64  `json:"verified_at"`
+	Status          int    `json:"status"`
+	Size            int64  `json:"size"`
+}
+
+// BlocksResponse is returned by GetBlocks
+type BlocksResponse struct {
+	status
+	Total  int64   `json:"total"`
+	Blocks []Block `json:"blocks"`
+}
+
+// Tx is an executed or pending tx. Info is the tx_info JSON that was
+// submitted.
+type Tx struct {
+	Hash             string `json:"hash"`
+	Type             uint8  `json:"type"`
+	Info             string `json:"info"`
+	EventInfo        string `json:"event_info"`
+	Status           int    `json:"status"`
+	TransactionIndex int64  `json:"transaction_index"`
+	L1Address        string `json:"l1_address"`
+	AccountIndex     int64  `json:"account_index"`
+	Nonce            int64  `json:"nonce"`
+	ExpireAt         int64  `json:"expire_at"`
+	BlockHeight      int64  `json:"block_height"`
+	QueuedAt         int64  `json:"queued_at"`
+	ExecutedAt       int64  `json:"executed_at"`
+	SequenceIndex    int64  `json:"sequence_index"`
+	ParentHash       string `json:"parent_hash"`
+}
+
+// TxResponse is returned by GetTransaction
+type TxResponse struct {
+	status
+	Tx
+}
+
+// SendTxResponse is returned by SendTx
+type SendTxResponse struct {
+	status
+	TxHash                   string `json:"tx_hash"`
+	PredictedExecutionTimeMs int64  `json:"predicted_execution_time_ms"`
+}
+
+// SendTxBatchResponse is returned by SendTxBatch
+type SendTxBatchResponse struct {
+	status
+	TxHash                   []string `json:"tx_hash"`
+	PredictedExecutionTimeMs int64    `json:"predicted_execution_time_ms"`
+}
+
+// GetBlocks fetches the latest limit blocks
+func (c *Client) GetBlocks(ctx context.Context, limit int) (*BlocksResponse, error) {
+	out := &BlocksResponse{}
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if err := c.get(ctx, "blocks", query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTransaction fetches a tx by hash
+func (c *Client) GetTransaction(ctx context.Context, txHash string) (*TxResponse, error) {
+	out := &TxResponse{}
+	query := url.Values{"tx_hash": {txHash}}
+	if err := c.get(ctx, "transaction", query, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SendTx submits one signed tx
+func (c *Client) SendTx(ctx context.Context, txType uint8, txInfo string) (*SendTxResponse, error) {
+	out := &SendTxResponse{}
+	form := url.Values{
+		"tx_type": {strconv.Itoa(int(txType))},
+		"tx_info": {txInfo},
+	}
+	if err := c.post(ctx, "sendTx", form, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SendTxBatch submits several signed txs; txTypes and txInfos are parallel
+func (c *Client) SendTxBatch(ctx context.Context, txTypes []uint8, txInfos []string) (*SendTxBatchResponse, error) {
+	types := make([]int, len(txTypes))
+	for i, t := range txTypes {
+		types[i] = int(t)
+	}
+	typesJSON, err := json.Marshal(types)
+	if err != nil {
+		return nil, err
+	}
+	infosJSON, err := json.Marshal(txInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SendTxBatchResponse{}
+	form := url.Values{
+		"tx_types": {string(typesJSON)},
+		"tx_infos": {string(infosJSON)},
+	}
+	if err := c.post(ctx, "sendTxBatch", form, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"lighter-wasm/signing"
+)
+
+// signBatch signs several txs with consecutive nonces in one call:
+// signBatch({session or key, startNonce, expiredAt?, txs: [{txType, params}]}).
+// Entry i is signed with nonce startNonce+i; a batch-level expiredAt applies
+// to entries that do not set their own. Nothing is returned unless every
+// entry signs, so a rejected batch never leaves a gap in the nonce sequence.
+func signBatch(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		return signBatchArgs(args)
+	})
+}
+
+func signBatchArgs(args []js.Value) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("Missing arguments: no parameters provided")
+	}
+
+	params := args[0]
+
+	var batch signing.BatchParams
+	if err := decodeParams(params, &batch); err != nil {
+		return nil, err
+	}
+
+	txs := params.Get("txs")
+	if txs.IsUndefined() || txs.IsNull() {
+		return nil, &paramError{Reason: reasonMissing, Field: "txs", Message: "is required"}
+	}
+	if !isArray(txs) {
+		return nil, &paramError{Reason: reasonType, Field: "txs", Message: "expected an array"}
+	}
+
+	reqs, err := signing.DecodeBatch(batchEntries{txs}, batch.StartNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := sessionFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := sess.SignBatch(reqs, batch.StartNonce, batch.ExpiredAt, time.Now())
+	if err != nil {
+		return nil, signing.QualifyBatchError(err)
+	}
+
+	n := len(signed)
+	results := make([]interface{}, n)
+	txTypes := make([]interface{}, n)
+	txInfos := make([]interface{}, n)
+	txHashes := make([]interface{}, n)
+	for i, r := range signed {
+		results[i] = resultValue(r)
+		txTypes[i] = int(r.TxType)
+		txInfos[i] = r.TxInfo
+		txHashes[i] = r.TxHash
+	}
+
+	return map[string]interface{}{
+		"txs":       results,
+		"txTypes":   txTypes,
+		"txInfos":   txInfos,
+		"txHashes":  txHashes,
+		"nextNonce": batch.StartNonce + int64(n),
+	}, nil
+}
+
+// batchEntries reads signBatch entries from a JS array
+type batchEntries struct {
+	txs js.Value
+}
+
+func (e batchEntries) Len() int {
+	return e.txs.Length()
+}
+
+func (e batchEntries) TxType(i int) (uint8, error) {
+	entry := e.txs.Index(i)
+	if jsTypeOf.Invoke(entry).String() != "object" || entry.IsNull() {
+		return 0, &paramError{Reason: reasonType, Message: "expected an object"}
+	}
+	txType, err := parseTxType(entry.Get("txType"))
+	if err != nil {
+		return 0, &paramError{Reason: reasonType, Field: "txType", Message: err.Error()}
+	}
+	return txType, nil
+}
+
+func (e batchEntries) DecodeParams(i int, req signing.TxRequest, nonce int64) error {
+	params := e.txs.Index(i).Get("params")
+	if params.IsUndefined() || params.IsNull() {
+		params = js.ValueOf(map[string]interface{}{})
+	}
+
+	// The sequential nonce goes last so it cannot be overridden;
+	// SignBatch assigns the same value.
+	params = js.Global().Get("Object").Call("assign",
+		js.ValueOf(map[string]interface{}{}),
+		params,
+		js.ValueOf(map[string]interface{}{"nonce": nonce}),
+	)
+	return decodeParams(params, req)
+}
@@ -50,7 +50,7 @@ REM Build WASM with all functions
 echo Building complete WASM binary...
 set GOOS=js
 set GOARCH=wasm
-go build -o ..\sdk\dist\lighter.wasm main.go additional_functions.go pool_functions.go
+go build -o ..\sdk\dist\lighter.wasm .
 
 if errorlevel 1 (
     echo ERROR: Build failed
@@ -79,6 +79,7 @@ echo   - signCreatePublicPool, signUpdatePublicPool
 echo   - signMintShares, signBurnShares
 echo   Auth:
 echo   - createAuthToken, generateKey, signChangePubKey
+echo   Full list: (await LighterWASM.info()).functions
 echo.
 echo To test:
 echo 1. cd ..\sdk
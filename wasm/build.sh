@@ -7,7 +7,7 @@ echo "🔨 Building Lighter WASM..."
 cp "$(go env GOROOT)/misc/wasm/wasm_exec.js" ../
 
 # Build WASM
-GOOS=js GOARCH=wasm go build -o ../lighter.wasm main.go
+GOOS=js GOARCH=wasm go build -o ../lighter.wasm .
 
 # Check size
 SIZE=$(stat -f%z "../lighter.wasm" 2>/dev/null || stat -c%s "../lighter.wasm" 2>/dev/null)
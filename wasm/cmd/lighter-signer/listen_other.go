@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "net"
+
+// listenPrivate creates the socket; without a umask, listenUnix restricts it
+// right after
+func listenPrivate(address string) (net.Listener, error) {
+	return net.Listen("unix", address)
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenPrivate creates the socket under a umask that leaves it usable by
+// the owner only, so no other user can connect before it is chmod'ed
+func listenPrivate(address string) (net.Listener, error) {
+	old := syscall.Umask(0o077)
+	defer syscall.Umask(old)
+	return net.Listen("unix", address)
+}
@@ -0,0 +1,307 @@
+// Command lighter-signer holds API keys in one process and signs txs for
+// local clients over JSON-RPC 2.0, so bots never see a private key. It
+// signs with the same signing package as lighter.wasm, and each sign
+// method returns the object the matching LighterWASM function resolves.
+//
+//	lighter-signer -chain-id 304 -key 5:3:key.json -listen unix:/run/lighter/signer.sock
+//	lighter-signer -chain-id 304 -key 5:3:key.json -listen tcp:127.0.0.1:7390 -token-file token
+//
+// Keys are keystores written by lighter keygen -o, decrypted at startup
+// with the password from LIGHTER_PASSWORD or -password-file. A unix socket
+// is created so only its owner can connect. A TCP port is limited to
+// loopback addresses, but any local user can reach it, so it needs a token
+// from LIGHTER_SIGNER_TOKEN or -token-file that every request must carry.
+// The daemon makes no network calls: requests carry their nonce. See
+// methods.go for the API.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"lighter-wasm/api"
+	"lighter-wasm/signing"
+)
+
+// keyID identifies a loaded key
+type keyID struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+}
+
+// keySpec is one -key flag: the key of an account's API key and the
+// keystore holding it
+type keySpec struct {
+	keyID
+	path string
+}
+
+// keyFlags collects repeated -key flags
+type keyFlags []keySpec
+
+func (k *keyFlags) String() string {
+	specs := make([]string, len(*k))
+	for i, spec := range *k {
+		specs[i] = fmt.Sprintf("%d:%d:%s", spec.accountIndex, spec.apiKeyIndex, spec.path)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (k *keyFlags) Set(v string) error {
+	parts := strings.SplitN(v, ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return fmt.Errorf("expected ACCOUNT:API_KEY:KEYSTORE, got %q", v)
+	}
+	accountIndex, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || accountIndex < 0 {
+		return fmt.Errorf("invalid account index %q", parts[0])
+	}
+	apiKeyIndex, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return fmt.Errorf("invalid API key index %q", parts[1])
+	}
+	*k = append(*k, keySpec{keyID{accountIndex, uint8(apiKeyIndex)}, parts[2]})
+	return nil
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags)
+	log.SetPrefix("lighter-signer: ")
+	if err := run(); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	fs := flag.NewFlagSet("lighter-signer", flag.ContinueOnError)
+	var keys keyFlags
+	chainId := fs.Uint("chain-id", api.MainnetChainId, "chain id the keys sign for")
+	listen := fs.String("listen", "unix:lighter-signer.sock", "unix:PATH, or tcp:HOST:PORT on a loopback address, which requires a token")
+	passwordFile := fs.String("password-file", os.Getenv("LIGHTER_PASSWORD_FILE"), "file holding the keystore password; LIGHTER_PASSWORD is used when unset (LIGHTER_PASSWORD_FILE)")
+	tokenFile := fs.String("token-file", os.Getenv("LIGHTER_SIGNER_TOKEN_FILE"), "file holding the token every request must carry; LIGHTER_SIGNER_TOKEN is used when unset (LIGHTER_SIGNER_TOKEN_FILE)")
+	fs.Var(&keys, "key", "ACCOUNT:API_KEY:KEYSTORE to load; repeat for more keys")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: lighter-signer -key ACCOUNT:API_KEY:KEYSTORE [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return err
+	}
+	if len(keys) == 0 || fs.NArg() > 0 || *chainId > 1<<32-1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	token, err := readToken(*tokenFile)
+	if err != nil {
+		return err
+	}
+	defer zeroize(token)
+
+	signers, err := loadKeys(keys, uint32(*chainId), *passwordFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, s := range signers {
+			s.Zeroize()
+		}
+	}()
+
+	ln, cleanup, err := listenOn(*listen, token != nil)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ln.Close()
+	}()
+
+	srv := newServer(uint32(*chainId), signers, token)
+	log.Printf("serving %d key(s) for chain %d on %s", len(signers), *chainId, *listen)
+	return srv.serve(ln)
+}
+
+// loadKeys decrypts every keystore with the one password
+func loadKeys(keys keyFlags, chainId uint32, passwordFile string) (map[keyID]*signing.Signer, error) {
+	password, err := readPassword(passwordFile)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroize(password)
+
+	signers := make(map[keyID]*signing.Signer, len(keys))
+	for _, spec := range keys {
+		s, err := loadKey(spec, chainId, password)
+		if err == nil {
+			if _, ok := signers[spec.keyID]; ok {
+				s.Zeroize()
+				err = fmt.Errorf("API key %d of account %d is given twice", spec.apiKeyIndex, spec.accountIndex)
+			}
+		}
+		if err != nil {
+			for _, s := range signers {
+				s.Zeroize()
+			}
+			return nil, err
+		}
+		signers[spec.keyID] = s
+	}
+	return signers, nil
+}
+
+func loadKey(spec keySpec, chainId uint32, password []byte) (*signing.Signer, error) {
+	blob, err := os.ReadFile(spec.path)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := signing.DecryptKey(blob, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", spec.path, err)
+	}
+	s, err := signing.NewSigner(privateKey, signing.Account{
+		ChainId:      chainId,
+		AccountIndex: spec.accountIndex,
+		ApiKeyIndex:  spec.apiKeyIndex,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", spec.path, err)
+	}
+	return s, nil
+}
+
+// readPassword reads the keystore password from a file, or from
+// LIGHTER_PASSWORD, which is then removed from the environment
+func readPassword(passwordFile string) ([]byte, error) {
+	if passwordFile == "" {
+		password, ok := os.LookupEnv("LIGHTER_PASSWORD")
+		if !ok {
+			return nil, fmt.Errorf("Missing password: set LIGHTER_PASSWORD or -password-file")
+		}
+		os.Unsetenv("LIGHTER_PASSWORD")
+		return []byte(password), nil
+	}
+
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+// minTokenSize keeps a request token from being guessable
+const minTokenSize = 16
+
+// readToken reads the request token from a file, or from
+// LIGHTER_SIGNER_TOKEN, which is then removed from the environment. It
+// returns nil if neither is set.
+func readToken(tokenFile string) ([]byte, error) {
+	var token []byte
+	if tokenFile == "" {
+		value, ok := os.LookupEnv("LIGHTER_SIGNER_TOKEN")
+		if !ok {
+			return nil, nil
+		}
+		os.Unsetenv("LIGHTER_SIGNER_TOKEN")
+		token = []byte(value)
+	} else {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = bytes.TrimRight(data, "\r\n")
+	}
+
+	if len(token) < minTokenSize {
+		return nil, fmt.Errorf("Invalid token: must be at least %d characters", minTokenSize)
+	}
+	return token, nil
+}
+
+// listenOn opens a unix socket, or a loopback TCP port if authenticated
+// is set, as a TCP port is open to every local user. cleanup removes the
+// socket file.
+func listenOn(addr string, authenticated bool) (net.Listener, func(), error) {
+	network, address, ok := strings.Cut(addr, ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("Invalid -listen %q: expected unix:PATH or tcp:HOST:PORT", addr)
+	}
+
+	switch network {
+	case "unix":
+		ln, err := listenUnix(address)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ln, func() { ln.Close(); os.Remove(address) }, nil
+
+	case "tcp":
+		if !authenticated {
+			return nil, nil, fmt.Errorf("Refusing to listen on TCP without a token: set LIGHTER_SIGNER_TOKEN or -token-file")
+		}
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return nil, nil, fmt.Errorf("Refusing to listen on %s: only loopback addresses are allowed", host)
+		}
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ln, func() { ln.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("Invalid -listen %q: network must be unix or tcp", addr)
+}
+
+// listenUnix opens a unix socket only the owner can use, replacing a stale
+// one left by a previous run
+func listenUnix(address string) (net.Listener, error) {
+	if fi, err := os.Stat(address); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", address)
+		}
+		if conn, err := net.Dial("unix", address); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", address)
+		}
+		if err := os.Remove(address); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := listenPrivate(address)
+	if err != nil {
+		return nil, err
+	}
+	// Also covers systems without a umask
+	if err := os.Chmod(address, 0o600); err != nil {
+		ln.Close()
+		os.Remove(address)
+		return nil, err
+	}
+	return ln, nil
+}
+
+func zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// The methods mirror the LighterWASM exports. Params are one object that
+// names the key with accountIndex and apiKeyIndex next to the tx params,
+// as the exports take a session alongside them; nonce is required.
+//
+//	info                   -> {chainId, keys: [{accountIndex, apiKeyIndex, publicKey}]}
+//	signTx                 {txType, ...params} -> signed tx
+//	sign<TxType>           {...params} -> signed tx, e.g. signCreateOrder
+//	signBatch              {startNonce, expiredAt?, txs: [{txType, params}]}
+//	                       -> {txs, txTypes, txInfos, txHashes, nextNonce}
+//	createAuthToken        {expiryHours?} -> token
+//
+// A signed tx is {txType, txInfo, txHash, nonce, expiredAt, accountIndex,
+// apiKeyIndex, l1Message?}, ready for sendTx. When the daemon has a token,
+// every request carries it next to method:
+//
+//	{"jsonrpc": "2.0", "id": 1, "token": "...", "method": "info"}
+
+// server signs for the loaded keys
+type server struct {
+	chainId uint32
+	signers map[keyID]*signing.Signer
+	methods map[string]method
+	// token is required on every request unless nil
+	token []byte
+}
+
+// keyParams names the key a request signs with
+type keyParams struct {
+	AccountIndex int64 `param:"accountIndex,min=0"`
+	ApiKeyIndex  uint8 `param:"apiKeyIndex"`
+}
+
+func newServer(chainId uint32, signers map[keyID]*signing.Signer, token []byte) *server {
+	s := &server{chainId: chainId, signers: signers, token: token}
+	s.methods = map[string]method{
+		"info":            s.info,
+		"signTx":          s.signTx,
+		"signBatch":       s.signBatch,
+		"createAuthToken": s.createAuthToken,
+	}
+	for _, txType := range signing.TxTypes() {
+		kind, _ := signing.KindOf(txType)
+		txType := txType
+		s.methods["sign"+methodSuffix(kind.Name)] = func(params json.RawMessage) (interface{}, error) {
+			return s.sign(txType, params)
+		}
+	}
+	return s
+}
+
+// methodSuffix turns a TX_TYPES name into its export's suffix, e.g.
+// CREATE_ORDER into CreateOrder
+func methodSuffix(name string) string {
+	words := strings.Split(strings.ToLower(name), "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// signer returns the loaded key params name
+func (s *server) signer(params json.RawMessage) (*signing.Signer, error) {
+	var key keyParams
+	if err := signing.DecodeParams(params, &key); err != nil {
+		return nil, err
+	}
+	sess, ok := s.signers[keyID{key.AccountIndex, key.ApiKeyIndex}]
+	if !ok {
+		return nil, signing.Errorf(errcodes.InvalidKey, "apiKeyIndex", nil, "No key loaded for API key %d of account %d", key.ApiKeyIndex, key.AccountIndex)
+	}
+	return sess, nil
+}
+
+func (s *server) info(json.RawMessage) (interface{}, error) {
+	type keyInfo struct {
+		AccountIndex int64  `json:"accountIndex"`
+		ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+		PublicKey    string `json:"publicKey"`
+	}
+	keys := make([]keyInfo, 0, len(s.signers))
+	for id, sess := range s.signers {
+		keys = append(keys, keyInfo{id.accountIndex, id.apiKeyIndex, hex.EncodeToString(sess.PublicKey())})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].AccountIndex != keys[j].AccountIndex {
+			return keys[i].AccountIndex < keys[j].AccountIndex
+		}
+		return keys[i].ApiKeyIndex < keys[j].ApiKeyIndex
+	})
+	return map[string]interface{}{"chainId": s.chainId, "keys": keys}, nil
+}
+
+func (s *server) sign(txType uint8, params json.RawMessage) (interface{}, error) {
+	sess, err := s.signer(params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := signing.NewTxRequest(txType, params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := sess.SignAt(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return signing.NewSignedTx(result), nil
+}
+
+func (s *server) signTx(params json.RawMessage) (interface{}, error) {
+	var head struct {
+		TxType json.RawMessage `json:"txType"`
+	}
+	if err := json.Unmarshal(params, &head); err != nil {
+		return nil, &signing.ParamError{Reason: signing.ReasonType, Field: "", Message: "expected an object"}
+	}
+	txType, err := parseTxType(head.TxType)
+	if err != nil {
+		return nil, err
+	}
+	return s.sign(txType, params)
+}
+
+// parseTxType accepts a TX_TYPES name or number as JSON
+func parseTxType(raw json.RawMessage) (uint8, error) {
+	if len(raw) == 0 {
+		return 0, &signing.ParamError{Reason: signing.ReasonMissing, Field: "txType", Message: "is required"}
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return signing.ParseTxType(name)
+	}
+	return signing.ParseTxType(string(raw))
+}
+
+func (s *server) signBatch(params json.RawMessage) (interface{}, error) {
+	var batch signing.BatchParams
+	if err := signing.DecodeParams(params, &batch); err != nil {
+		return nil, err
+	}
+	var body struct {
+		Txs []json.RawMessage `json:"txs"`
+	}
+	if err := json.Unmarshal(params, &body); err != nil {
+		return nil, &signing.ParamError{Reason: signing.ReasonType, Field: "txs", Message: "expected an array"}
+	}
+	if body.Txs == nil {
+		return nil, &signing.ParamError{Reason: signing.ReasonMissing, Field: "txs", Message: "is required"}
+	}
+
+	reqs, err := signing.DecodeBatch(batchEntries(body.Txs), batch.StartNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := s.signer(params)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := sess.SignBatch(reqs, batch.StartNonce, batch.ExpiredAt, time.Now())
+	if err != nil {
+		return nil, signing.QualifyBatchError(err)
+	}
+
+	n := len(signed)
+	txs := make([]*signing.SignedTx, n)
+	txTypes := make([]uint8, n)
+	txInfos := make([]string, n)
+	txHashes := make([]string, n)
+	for i, r := range signed {
+		txs[i] = signing.NewSignedTx(r)
+		txTypes[i] = r.TxType
+		txInfos[i] = r.TxInfo
+		txHashes[i] = r.TxHash
+	}
+	return map[string]interface{}{
+		"txs":       txs,
+		"txTypes":   txTypes,
+		"txInfos":   txInfos,
+		"txHashes":  txHashes,
+		"nextNonce": batch.StartNonce + int64(n),
+	}, nil
+}
+
+// batchEntries reads signBatch entries from their JSON
+type batchEntries []json.RawMessage
+
+// batchEntry is one {txType, params} entry
+type batchEntry struct {
+	TxType json.RawMessage `json:"txType"`
+	Params json.RawMessage `json:"params"`
+}
+
+func (e batchEntries) entry(i int) (*batchEntry, error) {
+	var entry batchEntry
+	if err := json.Unmarshal(e[i], &entry); err != nil || string(e[i]) == "null" {
+		return nil, &signing.ParamError{Reason: signing.ReasonType, Message: "expected an object"}
+	}
+	return &entry, nil
+}
+
+func (e batchEntries) Len() int {
+	return len(e)
+}
+
+func (e batchEntries) TxType(i int) (uint8, error) {
+	entry, err := e.entry(i)
+	if err != nil {
+		return 0, err
+	}
+	txType, err := parseTxType(entry.TxType)
+	if err != nil {
+		return 0, &signing.ParamError{Reason: signing.ReasonType, Field: "txType", Message: err.Error()}
+	}
+	return txType, nil
+}
+
+func (e batchEntries) DecodeParams(i int, req signing.TxRequest, nonce int64) error {
+	entry, err := e.entry(i)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]json.RawMessage)
+	if len(entry.Params) > 0 && string(entry.Params) != "null" {
+		if err := json.Unmarshal(entry.Params, &fields); err != nil {
+			return &signing.ParamError{Reason: signing.ReasonType, Message: "expected an object"}
+		}
+	}
+
+	// The sequential nonce replaces any the entry sets; SignBatch assigns
+	// the same value
+	fields["nonce"] = json.RawMessage(strconv.FormatInt(nonce, 10))
+	params, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return signing.DecodeParams(params, req)
+}
+
+func (s *server) createAuthToken(params json.RawMessage) (interface{}, error) {
+	sess, err := s.signer(params)
+	if err != nil {
+		return nil, err
+	}
+	var req signing.AuthToken
+	if err := signing.DecodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	return sess.AuthToken(req, time.Now())
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// JSON-RPC 2.0 error codes. Signing failures use codeSignFailed with the
+// errcodes code in data.code.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeSignFailed     = -32000
+	codeUnauthorized   = -32001
+)
+
+// maxRequestSize bounds one request so a client cannot exhaust memory
+const maxRequestSize = 1 << 20
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	// Token authenticates the request when the daemon has one
+	Token string `json:"token"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError carries the fields LighterWASM errors have in data
+type rpcError struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *errorData `json:"data,omitempty"`
+}
+
+type errorData struct {
+	Code   string `json:"code"`
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Cause  string `json:"cause,omitempty"`
+}
+
+// errorOf classifies err like the WASM bridge does: the innermost coded
+// error decides the code and the outermost field wins. Errors without a
+// code are input errors.
+func errorOf(err error) *rpcError {
+	data := &errorData{Code: errcodes.InvalidParam}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch t := e.(type) {
+		case *signing.ParamError:
+			data.Code, data.Reason, data.Cause = errcodes.InvalidParam, t.Reason, ""
+			if data.Field == "" {
+				data.Field = t.Field
+			}
+		case *signing.Error:
+			data.Code, data.Reason, data.Cause = t.Code, "", ""
+			if data.Field == "" {
+				data.Field = t.Field
+			}
+			if t.Cause != nil {
+				data.Cause = t.Cause.Error()
+			}
+		}
+	}
+
+	code := codeSignFailed
+	if data.Code == errcodes.InvalidParam {
+		code = codeInvalidParams
+	}
+	return &rpcError{code, err.Error(), data}
+}
+
+// method handles the params of one JSON-RPC method
+type method func(params json.RawMessage) (interface{}, error)
+
+// serve accepts connections until ln is closed
+func (s *server) serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn answers the requests of one connection in order. Requests and
+// responses are JSON values, conventionally one per line.
+func (s *server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	in := &budgetReader{r: conn}
+	dec := json.NewDecoder(in)
+	enc := json.NewEncoder(conn)
+	for {
+		in.budget = maxRequestSize
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err != io.EOF {
+				enc.Encode(&response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "Parse error: " + err.Error()}})
+			}
+			return
+		}
+
+		if resp := s.handle(raw); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle runs one request. Notifications, which have no id, get no
+// response.
+func (s *server) handle(raw json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeInvalidRequest, Message: "Invalid request"}}
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if !s.authorized(req.Token) {
+		resp.Error = &rpcError{Code: codeUnauthorized, Message: "Unauthorized: missing or invalid token"}
+		log.Printf("%s: rejected request without a valid token", req.Method)
+	} else if m, ok := s.methods[req.Method]; !ok {
+		resp.Error = &rpcError{Code: codeMethodNotFound, Message: "Method not found: " + req.Method}
+	} else if result, err := s.call(m, req.Params); err != nil {
+		resp.Error = errorOf(err)
+		log.Printf("%s: %v", req.Method, err)
+	} else {
+		resp.Result = result
+	}
+
+	if len(req.ID) == 0 {
+		return nil
+	}
+	return resp
+}
+
+// authorized reports whether token matches the daemon's, if it has one
+func (s *server) authorized(token string) bool {
+	if s.token == nil {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(token), s.token) == 1
+}
+
+// call runs m, turning a panic into an error
+func (s *server) call(m method, params json.RawMessage) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = signing.Errorf(errcodes.Panic, "", nil, "Panic: %v", r)
+		}
+	}()
+	return m(params)
+}
+
+// budgetReader fails once budget bytes have been read, which bounds the
+// size of a request
+type budgetReader struct {
+	r      io.Reader
+	budget int
+}
+
+var errTooLarge = errors.New("request too large")
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	if b.budget <= 0 {
+		return 0, errTooLarge
+	}
+	if len(p) > b.budget {
+		p = p[:b.budget]
+	}
+	n, err := b.r.Read(p)
+	b.budget -= n
+	return n, err
+}
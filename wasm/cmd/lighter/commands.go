@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"lighter-wasm/api"
+	"lighter-wasm/signing"
+)
+
+func zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+func runKeygen(args []string) error {
+	c := newConfig("keygen", "", false)
+	out := c.fs.String("o", "", "write an encrypted keystore to this file instead of printing the private key")
+	scryptN := c.fs.Int("scrypt-n", signing.DefaultScryptN, "scrypt cost of the keystore")
+	c.passwordFlag()
+	if err := c.parse(args, 0, 0); err != nil {
+		return err
+	}
+
+	privateKey, publicKey := signing.GenerateKey()
+	defer zeroize(privateKey)
+
+	if *out == "" {
+		return printJSON(map[string]string{
+			"privateKey": "0x" + hex.EncodeToString(privateKey),
+			"publicKey":  "0x" + hex.EncodeToString(publicKey),
+		})
+	}
+
+	password, err := c.password()
+	if err != nil {
+		return err
+	}
+	ks, err := signing.EncryptKey(privateKey, password, *scryptN)
+	if err != nil {
+		return err
+	}
+	blob, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+	// O_EXCL so an existing key is never overwritten
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(blob, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return printJSON(map[string]string{"publicKey": ks.PublicKey, "keystore": *out})
+}
+
+func runPubkey(args []string) error {
+	c := newConfig("pubkey", "", false)
+	c.keyFlags()
+	if err := c.parse(args, 0, 0); err != nil {
+		return err
+	}
+
+	privateKey, err := c.privateKey()
+	if err != nil {
+		return err
+	}
+	defer zeroize(privateKey)
+
+	publicKey, err := signing.PublicKey(privateKey)
+	if err != nil {
+		return err
+	}
+	fmt.Println("0x" + hex.EncodeToString(publicKey))
+	return nil
+}
+
+func runSign(args []string) error {
+	c := newConfig("sign", "<tx-type>", true)
+	paramsArg := c.fs.String("params", "-", "tx params as JSON, a JSON file, or - for stdin; nonce is fetched when omitted")
+	submit := c.fs.Bool("submit", false, "submit the signed tx and print the response too")
+	c.keyFlags()
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		// Allow the tx type before the flags
+		args = append(args[1:], args[0])
+	}
+	if err := c.parse(args, 1, 1); err != nil {
+		return err
+	}
+
+	txType, err := signing.ParseTxType(c.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	// Load the key first so -password-file - takes the first stdin line
+	sess, err := c.signer()
+	if err != nil {
+		return err
+	}
+	defer sess.Zeroize()
+
+	params, err := readInput(*paramsArg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	params, err = withNonce(params, func() (int64, error) {
+		return c.client().GetNextNonce(ctx, sess.AccountIndex, sess.ApiKeyIndex)
+	})
+	if err != nil {
+		return err
+	}
+	req, err := signing.NewTxRequest(txType, params)
+	if err != nil {
+		return err
+	}
+	result, err := sess.SignAt(req, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !*submit {
+		return printJSON(signing.NewSignedTx(result))
+	}
+	resp, err := c.client().SendTx(ctx, result.TxType, result.TxInfo)
+	if err != nil {
+		return err
+	}
+	return printJSON(map[string]interface{}{"tx": signing.NewSignedTx(result), "response": resp})
+}
+
+// withNonce adds the next nonce to params unless they set one
+func withNonce(params []byte, next func() (int64, error)) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(params, &obj); err != nil {
+		return nil, fmt.Errorf("Invalid params: %v", err)
+	}
+	if _, ok := obj["nonce"]; ok {
+		return params, nil
+	}
+
+	nonce, err := next()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch nonce: %v", err)
+	}
+	obj["nonce"] = json.RawMessage(fmt.Sprint(nonce))
+	return json.Marshal(obj)
+}
+
+func runSubmit(args []string) error {
+	c := newConfig("submit", "[file|-]", false)
+	if err := c.parse(args, 0, 1); err != nil {
+		return err
+	}
+
+	data, err := readInput(c.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var txs []signing.SignedTx
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &txs)
+	} else {
+		txs = make([]signing.SignedTx, 1)
+		err = json.Unmarshal(data, &txs[0])
+	}
+	if err != nil {
+		return fmt.Errorf("Invalid signed tx: %v", err)
+	}
+	for i, tx := range txs {
+		if tx.TxInfo == "" {
+			return fmt.Errorf("Invalid signed tx %d: txInfo is empty", i)
+		}
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if len(txs) == 1 {
+		resp, err := c.client().SendTx(ctx, txs[0].TxType, txs[0].TxInfo)
+		if err != nil {
+			return err
+		}
+		return printJSON(resp)
+	}
+
+	txTypes := make([]uint8, len(txs))
+	txInfos := make([]string, len(txs))
+	for i, tx := range txs {
+		txTypes[i] = tx.TxType
+		txInfos[i] = tx.TxInfo
+	}
+	resp, err := c.client().SendTxBatch(ctx, txTypes, txInfos)
+	if err != nil {
+		return err
+	}
+	return printJSON(resp)
+}
+
+func runCancelAll(args []string) error {
+	c := newConfig("cancel-all", "", true)
+	dryRun := c.fs.Bool("dry-run", false, "print the signed tx without submitting it")
+	c.keyFlags()
+	if err := c.parse(args, 0, 0); err != nil {
+		return err
+	}
+
+	sess, err := c.signer()
+	if err != nil {
+		return err
+	}
+	defer sess.Zeroize()
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	client := c.client()
+	nonce, err := client.GetNextNonce(ctx, sess.AccountIndex, sess.ApiKeyIndex)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch nonce: %v", err)
+	}
+
+	// TimeInForce 0 is CANCEL_ALL_TIF.IMMEDIATE
+	req := &signing.CancelAllOrders{TxOpts: signing.TxOpts{Nonce: nonce}}
+	result, err := sess.SignAt(req, time.Now())
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return printJSON(signing.NewSignedTx(result))
+	}
+
+	resp, err := client.SendTx(ctx, result.TxType, result.TxInfo)
+	if err != nil {
+		return err
+	}
+	return printJSON(map[string]interface{}{"tx": signing.NewSignedTx(result), "response": resp})
+}
+
+func runNonce(args []string) error {
+	c := newConfig("nonce", "", true)
+	if err := c.parse(args, 0, 0); err != nil {
+		return err
+	}
+	if c.accountIndex < 0 {
+		return fmt.Errorf("account index required: pass -account or set LIGHTER_ACCOUNT_INDEX")
+	}
+	if c.apiKeyIndex > 255 {
+		return fmt.Errorf("Invalid API key index: %d", c.apiKeyIndex)
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	nonce, err := c.client().GetNextNonce(ctx, c.accountIndex, uint8(c.apiKeyIndex))
+	if err != nil {
+		return err
+	}
+	fmt.Println(nonce)
+	return nil
+}
+
+func runAccount(args []string) error {
+	c := newConfig("account", "", true)
+	address := c.fs.String("address", "", "look the account up by L1 address instead of -account")
+	orders := c.fs.Bool("orders", false, "print the account's open orders instead")
+	if err := c.parse(args, 0, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+	client := c.client()
+
+	if *address != "" {
+		if *orders {
+			return fmt.Errorf("-orders needs -account, not -address")
+		}
+		resp, err := client.GetAccountByAddress(ctx, *address)
+		if err != nil {
+			return err
+		}
+		return printJSON(resp)
+	}
+
+	if c.accountIndex < 0 {
+		return fmt.Errorf("account index required: pass -account, -address or set LIGHTER_ACCOUNT_INDEX")
+	}
+	if *orders {
+		resp, err := client.GetOrders(ctx, c.accountIndex, api.AllMarkets, true)
+		if err != nil {
+			return err
+		}
+		return printJSON(resp)
+	}
+	resp, err := client.GetAccount(ctx, c.accountIndex)
+	if err != nil {
+		return err
+	}
+	return printJSON(resp)
+}
+
+func runDecode(args []string) error {
+	c := newConfig("decode", "<tx-type> <tx-info|file|->", false)
+	pubKeyHex := c.fs.String("pubkey", "", "verify the signature against this public key")
+	if len(args) > 1 && !strings.HasPrefix(args[0], "-") {
+		args = append(args[2:], args[:2]...)
+	}
+	if err := c.parse(args, 2, 2); err != nil {
+		return err
+	}
+
+	txType, err := signing.ParseTxType(c.fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	txInfo, err := readInput(c.fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	tx, sig, err := signing.ParseTx(txType, strings.TrimSpace(string(txInfo)))
+	if err != nil {
+		return err
+	}
+	fields, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	kind, _ := signing.KindOf(txType)
+	chainId := c.chain()
+
+	result := map[string]interface{}{
+		"txType":     txType,
+		"txTypeName": kind.Name,
+		"chainId":    chainId,
+		"fields":     json.RawMessage(fields),
+		"sig":        "0x" + hex.EncodeToString(sig),
+	}
+
+	if *pubKeyHex == "" {
+		msgHash, err := tx.Hash(chainId)
+		if err != nil {
+			return err
+		}
+		result["txHash"] = hex.EncodeToString(msgHash)
+		return printJSON(result)
+	}
+
+	pubKey, err := signing.DecodeKey(*pubKeyHex)
+	if err != nil || len(pubKey) != signing.KeySize {
+		return fmt.Errorf("Invalid public key: expected %d hex-encoded bytes", signing.KeySize)
+	}
+	msgHash, verifyErr := signing.VerifyTx(tx, sig, chainId, pubKey)
+	if msgHash == nil {
+		return verifyErr
+	}
+	result["txHash"] = hex.EncodeToString(msgHash)
+	result["valid"] = verifyErr == nil
+	if verifyErr != nil {
+		result["error"] = verifyErr.Error()
+	}
+	if err := printJSON(result); err != nil {
+		return err
+	}
+	if verifyErr != nil {
+		return fmt.Errorf("signature does not verify")
+	}
+	return nil
+}
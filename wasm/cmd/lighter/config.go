@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"lighter-wasm/api"
+	"lighter-wasm/signing"
+)
+
+// errUsage reports bad arguments after the flag set printed its usage
+var errUsage = errors.New("usage")
+
+// config holds the flags shared by commands. Each flag defaults to its
+// environment variable.
+type config struct {
+	fs *flag.FlagSet
+
+	url          string
+	chainId      uint
+	accountIndex int64
+	apiKeyIndex  uint
+	keystore     string
+	passwordFile string
+	timeout      time.Duration
+}
+
+// newConfig returns the flag set of a command. account registers the
+// account flags; commands that sign add keyFlags.
+func newConfig(name, args string, account bool) *config {
+	c := &config{fs: flag.NewFlagSet(name, flag.ContinueOnError)}
+	c.fs.Usage = func() {
+		fmt.Fprintf(c.fs.Output(), "Usage: lighter %s [flags] %s\n\nFlags:\n", name, args)
+		c.fs.PrintDefaults()
+	}
+
+	c.fs.StringVar(&c.url, "url", envString("LIGHTER_URL", api.MainnetURL), "API base URL (LIGHTER_URL)")
+	c.fs.UintVar(&c.chainId, "chain-id", uint(envInt("LIGHTER_CHAIN_ID", 0)), "chain id; 0 picks it from -url (LIGHTER_CHAIN_ID)")
+	c.fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "request timeout")
+	if account {
+		c.fs.Int64Var(&c.accountIndex, "account", envInt("LIGHTER_ACCOUNT_INDEX", -1), "account index (LIGHTER_ACCOUNT_INDEX)")
+		c.fs.UintVar(&c.apiKeyIndex, "api-key", uint(envInt("LIGHTER_API_KEY_INDEX", 0)), "API key index (LIGHTER_API_KEY_INDEX)")
+	}
+	return c
+}
+
+// keyFlags registers the flags that locate the private key
+func (c *config) keyFlags() {
+	c.fs.StringVar(&c.keystore, "keystore", os.Getenv("LIGHTER_KEYSTORE"), "keystore file; LIGHTER_PRIVATE_KEY is used when unset (LIGHTER_KEYSTORE)")
+	c.passwordFlag()
+}
+
+// passwordFlag registers the keystore password flag
+func (c *config) passwordFlag() {
+	c.fs.StringVar(&c.passwordFile, "password-file", os.Getenv("LIGHTER_PASSWORD_FILE"), "file holding the keystore password; LIGHTER_PASSWORD is used when unset (LIGHTER_PASSWORD_FILE)")
+}
+
+// parse parses args and checks the number of positional arguments
+func (c *config) parse(args []string, minArgs, maxArgs int) error {
+	if err := c.fs.Parse(args); err != nil {
+		return err
+	}
+	if n := c.fs.NArg(); n < minArgs || n > maxArgs {
+		c.fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func envString(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(name string, def int64) int64 {
+	n, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func (c *config) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+func (c *config) client() *api.Client {
+	return api.NewClient(c.url)
+}
+
+// chain resolves -chain-id, defaulting to the chain of the testnet or
+// mainnet URL
+func (c *config) chain() uint32 {
+	if c.chainId != 0 {
+		return uint32(c.chainId)
+	}
+	if strings.TrimSuffix(c.url, "/") == api.TestnetURL {
+		return api.TestnetChainId
+	}
+	return api.MainnetChainId
+}
+
+// password reads the keystore password from -password-file, "-" meaning
+// the first line of stdin, or LIGHTER_PASSWORD
+func (c *config) password() ([]byte, error) {
+	switch c.passwordFile {
+	case "":
+		if p := os.Getenv("LIGHTER_PASSWORD"); p != "" {
+			return []byte(p), nil
+		}
+		return nil, fmt.Errorf("keystore password required: set LIGHTER_PASSWORD or -password-file")
+	case "-":
+		line, err := readLine(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read password: %v", err)
+		}
+		return []byte(line), nil
+	}
+
+	data, err := os.ReadFile(c.passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read password: %v", err)
+	}
+	return []byte(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+func readLine(r io.Reader) (string, error) {
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			b.WriteByte(buf[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(b.String(), "\r"), nil
+}
+
+// privateKey loads the raw key from -keystore or LIGHTER_PRIVATE_KEY. The
+// caller owns, and should zeroize, the returned bytes.
+func (c *config) privateKey() ([]byte, error) {
+	if c.keystore == "" {
+		hexKey := os.Getenv("LIGHTER_PRIVATE_KEY")
+		if hexKey == "" {
+			return nil, fmt.Errorf("no key: pass -keystore or set LIGHTER_PRIVATE_KEY")
+		}
+		key, err := signing.DecodeKey(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid private key: %v", err)
+		}
+		return key, nil
+	}
+
+	blob, err := os.ReadFile(c.keystore)
+	if err != nil {
+		return nil, err
+	}
+	password, err := c.password()
+	if err != nil {
+		return nil, err
+	}
+	return signing.DecryptKey(blob, password)
+}
+
+// signer loads the key and binds it to -account and -api-key
+func (c *config) signer() (*signing.Signer, error) {
+	if c.accountIndex < 0 {
+		return nil, fmt.Errorf("account index required: pass -account or set LIGHTER_ACCOUNT_INDEX")
+	}
+	if c.apiKeyIndex > 255 {
+		return nil, fmt.Errorf("Invalid API key index: %d", c.apiKeyIndex)
+	}
+
+	key, err := c.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	return signing.NewSigner(key, signing.Account{
+		ChainId:      c.chain(),
+		AccountIndex: c.accountIndex,
+		ApiKeyIndex:  uint8(c.apiKeyIndex),
+	})
+}
+
+// readInput returns arg itself when it looks like JSON, stdin for "-" or
+// "", and the named file otherwise
+func readInput(arg string) ([]byte, error) {
+	trimmed := strings.TrimSpace(arg)
+	switch {
+	case trimmed == "" || trimmed == "-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		return []byte(trimmed), nil
+	}
+	return os.ReadFile(arg)
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
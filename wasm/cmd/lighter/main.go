@@ -0,0 +1,76 @@
+// Command lighter runs one-off account operations from a terminal: key
+// generation, signing, submission and inspection. It signs with the same
+// signing package as lighter.wasm, so its txs match the browser SDK's.
+//
+//	lighter keygen -o key.json
+//	lighter sign CREATE_ORDER -params order.json > signed.json
+//	lighter submit signed.json
+//	lighter cancel-all
+//
+// Keys come from -keystore (password from LIGHTER_PASSWORD or
+// -password-file) or from LIGHTER_PRIVATE_KEY. Every flag that names the
+// account also reads an environment variable; see lighter <command> -h.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"keygen", "generate an API key pair", runKeygen},
+	{"pubkey", "print the public key of the configured key", runPubkey},
+	{"sign", "sign a tx and print it as JSON", runSign},
+	{"submit", "submit txs printed by sign", runSubmit},
+	{"cancel-all", "cancel every open order of the account", runCancelAll},
+	{"nonce", "print the next nonce of the API key", runNonce},
+	{"account", "print an account", runAccount},
+	{"decode", "decode and verify a signed tx", runDecode},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: lighter <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun lighter <command> -h for its flags.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(os.Args[2:])
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, flag.ErrHelp):
+			os.Exit(0)
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "lighter %s: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	if name != "-h" && name != "help" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "lighter: unknown command %q\n\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
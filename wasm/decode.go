@@ -0,0 +1,207 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// defaultChainId is used by decodeTx and verifyTx when the caller does not
+// pass one. It matches ENDPOINTS.mainnet.chainId in sdk/core/constants.js.
+const defaultChainId = 304
+
+// readableTx converts a tx into a plain object with byte fields in hex and
+// enum fields annotated with their constants.js names. Integers that do not
+// fit in a JS number are kept as decimal strings.
+func readableTx(txType uint8, tx txtypes.TxInfo, sig []byte) (map[string]interface{}, error) {
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	convertNumbers(fields)
+
+	fields["Sig"] = "0x" + hex.EncodeToString(sig)
+	switch t := tx.(type) {
+	case *txtypes.L2ChangePubKeyTxInfo:
+		fields["PubKey"] = "0x" + hex.EncodeToString(t.PubKey)
+	case *txtypes.L2TransferTxInfo:
+		fields["Memo"] = strings.TrimRight(string(t.Memo[:]), "\x00")
+	case *txtypes.L2CreateOrderTxInfo:
+		annotateOrder(fields)
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		annotate(fields, "GroupingType", &signing.GroupingTypes)
+		if orders, ok := fields["Orders"].([]interface{}); ok {
+			for _, order := range orders {
+				if m, ok := order.(map[string]interface{}); ok {
+					annotateOrder(m)
+				}
+			}
+		}
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		annotate(fields, "TimeInForce", &signing.CancelAllTIFs)
+	case *txtypes.L2UpdateLeverageTxInfo:
+		annotate(fields, "MarginMode", &signing.MarginModes)
+	case *txtypes.L2UpdateMarginTxInfo:
+		annotate(fields, "Direction", &signing.MarginDirs)
+	case *txtypes.L2UpdatePublicPoolTxInfo:
+		annotate(fields, "Status", &signing.PoolStatuses)
+	}
+
+	return map[string]interface{}{
+		"txType":     int(txType),
+		"txTypeName": txName(txType),
+		"fields":     fields,
+	}, nil
+}
+
+func annotateOrder(order map[string]interface{}) {
+	annotate(order, "Type", &signing.OrderTypes)
+	annotate(order, "IsAsk", &signing.OrderSides)
+	annotate(order, "TimeInForce", &signing.TimeInForces)
+}
+
+// annotate adds <key>Name next to an enum field, e.g. TypeName: "LIMIT"
+func annotate(fields map[string]interface{}, key string, e *signing.Enum) {
+	v, ok := fields[key].(int64)
+	if !ok {
+		return
+	}
+	if name, ok := e.Lookup(v); ok {
+		fields[key+"Name"] = name
+	}
+}
+
+// convertNumbers replaces json.Number values with int64 when they are safe
+// JS integers and with their decimal string otherwise.
+func convertNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, item := range t {
+			t[k] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = convertNumbers(item)
+		}
+	case json.Number:
+		if n, err := t.Int64(); err == nil && n <= maxSafeInteger && n >= -maxSafeInteger {
+			return n
+		}
+		return t.String()
+	}
+	return v
+}
+
+// txArgs parses the (txType, txInfo[, chainId]) arguments shared by
+// decodeTx and verifyTx. chainIdArg is the position of the chainId argument.
+func txArgs(args []js.Value, chainIdArg int) (uint8, string, uint32, error) {
+	if len(args) < 2 {
+		return 0, "", 0, fmt.Errorf("Missing arguments: txType and txInfo required")
+	}
+
+	txType, err := parseTxType(args[0])
+	if err != nil {
+		return 0, "", 0, err
+	}
+	if args[1].Type() != js.TypeString {
+		return 0, "", 0, fmt.Errorf("Invalid tx info: expected JSON string")
+	}
+
+	chainId := uint32(defaultChainId)
+	if len(args) > chainIdArg && !args[chainIdArg].IsUndefined() {
+		n, err := parseInteger(args[chainIdArg])
+		if err != nil || !n.IsUint64() || n.Uint64() > math.MaxUint32 {
+			return 0, "", 0, fmt.Errorf("Invalid chain id")
+		}
+		chainId = uint32(n.Uint64())
+	}
+
+	return txType, args[1].String(), chainId, nil
+}
+
+// decodeTx parses a signed tx_info blob into a readable object:
+// decodeTx(txType, txInfo[, chainId])
+func decodeTx(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		txType, txInfo, chainId, err := txArgs(args, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		tx, sig, err := signing.ParseTx(txType, txInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := readableTx(txType, tx, sig)
+		if err != nil {
+			return nil, signing.Errorf(errcodes.MarshalFailed, "", err, "Failed to decode tx")
+		}
+
+		msgHash, err := tx.Hash(chainId)
+		if err != nil {
+			return nil, signing.Errorf(errcodes.SignFailed, "", err, "Failed to hash tx")
+		}
+		result["txHash"] = hex.EncodeToString(msgHash)
+		result["chainId"] = int(chainId)
+
+		return result, nil
+	})
+}
+
+// verifyTx checks the Schnorr signature of a signed tx_info blob:
+// verifyTx(txType, txInfo, publicKey[, chainId]). Resolves {valid, txHash,
+// error}; rejects only for malformed input.
+func verifyTx(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		txType, txInfo, chainId, err := txArgs(args, 3)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(args) < 3 || args[2].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: publicKey required")
+		}
+		pubKey, err := decodePublicKey(args[2].String())
+		if err != nil {
+			return nil, err
+		}
+
+		tx, sig, err := signing.ParseTx(txType, txInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		msgHash, err := signing.VerifyTx(tx, sig, chainId, pubKey)
+		if msgHash == nil {
+			return nil, err
+		}
+
+		result := map[string]interface{}{
+			"valid":  err == nil,
+			"txHash": hex.EncodeToString(msgHash),
+		}
+		if err != nil {
+			result["error"] = err.Error()
+		}
+		return result, nil
+	})
+}
@@ -0,0 +1,123 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"syscall/js"
+
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"golang.org/x/crypto/hkdf"
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// keyDerivationMessage is the text a wallet personal_signs to produce the
+// seed for deriveKeyFromSeed. Changing it changes every derived key.
+const keyDerivationMessage = "Sign this message to derive your Lighter API keys.\n\nThis signature is not a transaction and costs no gas. Only sign it on a site you trust."
+
+// keyDerivationSalt versions the derivation itself
+const keyDerivationSalt = "lighter-api-key-derivation-v1"
+
+// minSeedSize rejects seeds too short to carry 128 bits of entropy with margin
+const minSeedSize = 32
+
+// deriveKeyParams checks the API key index argument of deriveKeyFromSeed
+type deriveKeyParams struct {
+	ApiKeyIndex uint8 `param:"apiKeyIndex"`
+}
+
+// deriveScalar maps a seed and API key index to a nonzero ecgfp5 scalar.
+// HKDF-SHA256 expands the seed to 64 bytes, which are reduced modulo the
+// group order; the 193 surplus bits keep the reduction bias negligible.
+func deriveScalar(seed []byte, apiKeyIndex uint8) (curve.ECgFp5Scalar, error) {
+	info := []byte(fmt.Sprintf("api-key-index:%d", apiKeyIndex))
+	okm := make([]byte, 64)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, seed, []byte(keyDerivationSalt), info), okm); err != nil {
+		return curve.ECgFp5Scalar{}, err
+	}
+
+	n := new(big.Int).SetBytes(okm)
+	n.Mod(n, curve.ORDER)
+	if n.Sign() == 0 {
+		return curve.ECgFp5Scalar{}, fmt.Errorf("derived a zero scalar")
+	}
+
+	// big.Int is big-endian; scalars are encoded little-endian
+	le := n.FillBytes(make([]byte, signing.KeySize))
+	for i, j := 0, len(le)-1; i < j; i, j = i+1, j-1 {
+		le[i], le[j] = le[j], le[i]
+	}
+	return curve.ScalarElementFromLittleEndianBytes(le), nil
+}
+
+// seedArg accepts a seed as a hex string (e.g. a 0x-prefixed wallet
+// signature) or a Uint8Array
+func seedArg(v js.Value) ([]byte, error) {
+	var seed []byte
+	switch {
+	case v.Type() == js.TypeString:
+		b, err := hex.DecodeString(strings.TrimPrefix(v.String(), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid seed: %v", err)
+		}
+		seed = b
+	case v.InstanceOf(js.Global().Get("Uint8Array")):
+		seed = make([]byte, v.Length())
+		js.CopyBytesToGo(seed, v)
+	default:
+		return nil, fmt.Errorf("Missing arguments: seed required as hex string or Uint8Array")
+	}
+
+	if len(seed) < minSeedSize {
+		return nil, fmt.Errorf("Invalid seed: expected at least %d bytes, got %d", minSeedSize, len(seed))
+	}
+	return seed, nil
+}
+
+// deriveKeyFromSeed deterministically derives the API key for an index:
+// deriveKeyFromSeed(seed, apiKeyIndex). The seed is typically the wallet's
+// personal_sign signature over keyDerivationMessage, so the same wallet
+// regenerates the same keys. Resolves {privateKey, publicKey, apiKeyIndex}.
+func deriveKeyFromSeed(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("Missing arguments: seed and apiKeyIndex required")
+		}
+
+		seed, err := seedArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for i := range seed {
+				seed[i] = 0
+			}
+		}()
+
+		var p deriveKeyParams
+		if err := decodeParams(js.ValueOf(map[string]interface{}{"apiKeyIndex": args[1]}), &p); err != nil {
+			return nil, err
+		}
+		apiKeyIndex := p.ApiKeyIndex
+
+		key, err := deriveScalar(seed, apiKeyIndex)
+		if err != nil {
+			return nil, signing.Errorf(errcodes.Internal, "", err, "Failed to derive key")
+		}
+		pk := schnorr.SchnorrPkFromSk(key)
+
+		return map[string]interface{}{
+			"privateKey":  "0x" + hex.EncodeToString(key.ToLittleEndianBytes()),
+			"publicKey":   "0x" + hex.EncodeToString(pk.ToLittleEndianBytes()),
+			"apiKeyIndex": int(apiKeyIndex),
+		}, nil
+	})
+}
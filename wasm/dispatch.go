@@ -0,0 +1,96 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// parseTxType accepts a numeric tx type or its TX_TYPES name
+func parseTxType(v js.Value) (uint8, error) {
+	if jsTypeOf.Invoke(v).String() == "string" {
+		if txType, ok := signing.TxTypeByName(v.String()); ok {
+			return txType, nil
+		}
+	}
+
+	n, err := parseInteger(v)
+	if err != nil || !n.IsUint64() || n.Uint64() > 255 {
+		return 0, fmt.Errorf("Invalid tx type: %v", js.Global().Get("String").Invoke(v).String())
+	}
+	return uint8(n.Uint64()), nil
+}
+
+// signParams decodes params into the request of txType, signs it and
+// returns its resultValue
+func signParams(txType uint8, params js.Value) (map[string]interface{}, error) {
+	req, err := decodeTxRequest(txType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := sessionFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := sess.SignAt(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return resultValue(result), nil
+}
+
+// decodeTxRequest decodes params into a new request for txType
+func decodeTxRequest(txType uint8, params js.Value) (signing.TxRequest, error) {
+	kind, ok := signing.KindOf(txType)
+	if !ok {
+		return nil, signing.Errorf(errcodes.InvalidParam, "txType", nil, "Unsupported tx type: %d", txType)
+	}
+
+	req := kind.NewRequest()
+	if err := decodeParams(params, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// signExport is the body shared by the per-type sign* exports
+func signExport(txType uint8, args []js.Value) js.Value {
+	return newPromise(func() (interface{}, error) {
+		return signArgs(txType, args)
+	})
+}
+
+func signArgs(txType uint8, args []js.Value) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("Missing arguments: no parameters provided")
+	}
+	return signParams(txType, args[0])
+}
+
+// signTx signs any supported tx type: signTx(txType, params). txType is a
+// TX_TYPES value or name; params are those of the matching sign* function.
+func signTx(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		return signTxArgs(args)
+	})
+}
+
+func signTxArgs(args []js.Value) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("Missing arguments: txType and params required")
+	}
+
+	txType, err := parseTxType(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return signParams(txType, args[1])
+}
@@ -0,0 +1,47 @@
+// Package errcodes is the catalog of error codes LighterWASM attaches to
+// rejected promises and thrown errors. sdk/core/errors.js is generated from
+// it, so codes are added here and nowhere else.
+package errcodes
+
+//go:generate go run gen.go
+
+const (
+	// InvalidKey: a private key, keystore, password or session handle is
+	// malformed, unknown or does not match
+	InvalidKey = "INVALID_KEY"
+	// InvalidParam: a call argument is missing or out of range
+	InvalidParam = "INVALID_PARAM"
+	// SignFailed: lighter-go or the L1 signer could not sign or hash a tx
+	SignFailed = "SIGN_FAILED"
+	// MarshalFailed: a signed result could not be encoded
+	MarshalFailed = "MARSHAL_FAILED"
+	// NonceNotSeeded: a nonce manager call was made for an account and API
+	// key before seedNonce
+	NonceNotSeeded = "NONCE_NOT_SEEDED"
+	// NonceStale: rolling a nonce back would hand out again a nonce that
+	// was already committed; the sequence must be resynced
+	NonceStale = "NONCE_STALE"
+	// Internal: the signer failed for reasons unrelated to the input, e.g.
+	// the random source
+	Internal = "INTERNAL"
+	// Panic: the Go side panicked; the message holds the panic value
+	Panic = "PANIC"
+)
+
+// Code describes one error code
+type Code struct {
+	Name        string
+	Description string
+}
+
+// Catalog lists every code in the order the SDK documents them
+var Catalog = []Code{
+	{InvalidKey, "Invalid private key, keystore or session"},
+	{InvalidParam, "Invalid or missing parameter"},
+	{SignFailed, "Failed to sign transaction"},
+	{MarshalFailed, "Failed to encode signed transaction"},
+	{NonceNotSeeded, "Nonce manager not seeded for this account and API key"},
+	{NonceStale, "Nonce sequence out of date, resync from the server"},
+	{Internal, "Internal signer error"},
+	{Panic, "Signer panicked"},
+}
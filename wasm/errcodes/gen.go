@@ -0,0 +1,47 @@
+//go:build ignore
+// +build ignore
+
+// gen writes sdk/core/errors.js from the errcodes catalog:
+//
+//	cd wasm/errcodes && go generate
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"lighter-wasm/errcodes"
+)
+
+const output = "../../sdk/core/errors.js"
+
+func main() {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by wasm/errcodes/gen.go; DO NOT EDIT.\n\n")
+	b.WriteString("// Values of the code property of errors from LighterWASM\n")
+	b.WriteString("export const WASM_ERROR_CODES = Object.freeze({\n")
+	for i, c := range errcodes.Catalog {
+		fmt.Fprintf(&b, "    %s: '%s'%s\n", c.Name, c.Name, separator(i))
+	}
+	b.WriteString("});\n\n")
+	b.WriteString("// Description of each code, merged into ERRORS\n")
+	b.WriteString("export const WASM_ERRORS = {\n")
+	for i, c := range errcodes.Catalog {
+		fmt.Fprintf(&b, "    %s: '%s'%s\n", c.Name, c.Description, separator(i))
+	}
+	b.WriteString("};\n")
+
+	if err := os.WriteFile(output, b.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// separator follows constants.js: no comma after the last entry
+func separator(i int) string {
+	if i == len(errcodes.Catalog)-1 {
+		return ""
+	}
+	return ","
+}
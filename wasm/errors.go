@@ -0,0 +1,72 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// panicError reports a recovered panic value
+func panicError(r interface{}) error {
+	return signing.Errorf(errcodes.Panic, "", nil, "Panic: %v", r)
+}
+
+// rejectError rejects a promise with the JS form of err
+func rejectError(reject js.Value, err error) {
+	reject.Invoke(errorValue(err))
+}
+
+// errorValue converts err into the Error JavaScript receives as a rejection
+// reason or thrown value: {name, message, code, field?, reason?, cause?}.
+// The innermost coded error in the chain decides the code, so wrapping for
+// context keeps the original classification, while the outermost field
+// wins so a wrapper can qualify it (e.g. txs[2].params.l1PrivateKey).
+// Errors without a code are input errors and get INVALID_PARAM.
+func errorValue(err error) js.Value {
+	code, field, reason, cause := errcodes.InvalidParam, "", "", ""
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		switch t := e.(type) {
+		case *paramError:
+			code, reason, cause = errcodes.InvalidParam, t.Reason, ""
+			if field == "" {
+				field = t.Field
+			}
+		case *signing.Error:
+			code, reason, cause = t.Code, "", ""
+			if field == "" {
+				field = t.Field
+			}
+			if t.Cause != nil {
+				cause = t.Cause.Error()
+			}
+		}
+	}
+
+	jsErr := js.Global().Get("Error").New(err.Error())
+	jsErr.Set("name", "LighterWASMError")
+	jsErr.Set("code", code)
+	if field != "" {
+		jsErr.Set("field", field)
+	}
+	if reason != "" {
+		jsErr.Set("reason", reason)
+	}
+	if cause != "" {
+		jsErr.Set("cause", cause)
+	}
+	return jsErr
+}
+
+// errorCatalog is errcodes.Catalog as {code: description}
+func errorCatalog() map[string]interface{} {
+	catalog := make(map[string]interface{}, len(errcodes.Catalog))
+	for _, c := range errcodes.Catalog {
+		catalog[c.Name] = c.Description
+	}
+	return catalog
+}
@@ -7,6 +7,9 @@ replace github.com/elliottech/lighter-go => ../lighter-go-main
 require (
 	github.com/elliottech/lighter-go v0.0.0
 	github.com/elliottech/poseidon_crypto v0.0.11
+	github.com/ethereum/go-ethereum v1.15.6
+	github.com/gorilla/websocket v1.5.3
+	golang.org/x/crypto v0.35.0
 )
 
 require (
@@ -17,12 +20,10 @@ require (
 	github.com/crate-crypto/go-kzg-4844 v1.1.0 // indirect
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 	github.com/ethereum/c-kzg-4844 v1.0.0 // indirect
-	github.com/ethereum/go-ethereum v1.15.6 // indirect
 	github.com/ethereum/go-verkle v0.2.2 // indirect
 	github.com/holiman/uint256 v1.3.2 // indirect
 	github.com/mmcloughlin/addchain v0.4.0 // indirect
 	github.com/supranational/blst v0.3.14 // indirect
-	golang.org/x/crypto v0.35.0 // indirect
 	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	rsc.io/tmplfunc v0.0.3 // indirect
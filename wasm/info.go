@@ -0,0 +1,120 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"syscall/js"
+
+	"lighter-wasm/signing"
+)
+
+// protocolVersion is bumped whenever an export changes its arguments or
+// result in a way older SDKs cannot handle. It must match
+// WASM_PROTOCOL_VERSION in sdk/core/constants.js.
+const protocolVersion = 1
+
+// exportedFunctions lists the LighterWASM functions, filled in by main
+var exportedFunctions []string
+
+// functionNames returns the sorted names of the functions in exports, with
+// nested maps such as sync listed as "sync.signTx"
+func functionNames(exports map[string]interface{}, prefix string) []string {
+	var names []string
+	for name, v := range exports {
+		switch v := v.(type) {
+		case js.Func:
+			names = append(names, prefix+name)
+		case js.Value:
+			if v.Type() == js.TypeFunction {
+				names = append(names, prefix+name)
+			}
+		case map[string]interface{}:
+			names = append(names, functionNames(v, prefix+name+".")...)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildInfo describes the binary: the main module, the VCS stamp and every
+// dependency with its version and any replace directive
+func buildInfo() map[string]interface{} {
+	result := map[string]interface{}{
+		"goVersion": runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return result
+	}
+
+	result["path"] = bi.Main.Path
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			result["revision"] = s.Value
+		case "vcs.time":
+			result["revisionTime"] = s.Value
+		case "vcs.modified":
+			result["modified"] = s.Value == "true"
+		}
+	}
+
+	modules := make(map[string]interface{}, len(bi.Deps))
+	for _, dep := range bi.Deps {
+		module := map[string]interface{}{
+			"version": dep.Version,
+		}
+		if dep.Replace != nil {
+			replace := map[string]interface{}{
+				"path": dep.Replace.Path,
+			}
+			if dep.Replace.Version != "" {
+				replace["version"] = dep.Replace.Version
+			}
+			module["replace"] = replace
+		}
+		modules[dep.Path] = module
+	}
+	result["modules"] = modules
+	return result
+}
+
+// txName returns the TX_TYPES name of a supported tx type
+func txName(txType uint8) string {
+	kind, _ := signing.KindOf(txType)
+	return kind.Name
+}
+
+// info describes this build for bug reports and compatibility checks:
+// info(). Resolves {protocolVersion, goVersion, path, revision,
+// revisionTime, modified, modules, txTypes, functions, selfTestPassed}.
+func info(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		result := buildInfo()
+		result["protocolVersion"] = protocolVersion
+		result["selfTestPassed"] = selfTestPassed(selfTestResults)
+
+		txTypes := signing.TxTypes()
+		kinds := make([]interface{}, len(txTypes))
+		for i, txType := range txTypes {
+			kinds[i] = map[string]interface{}{
+				"txType": int(txType),
+				"name":   txName(txType),
+			}
+		}
+		result["txTypes"] = kinds
+
+		functions := make([]interface{}, len(exportedFunctions))
+		for i, name := range exportedFunctions {
+			functions[i] = name
+		}
+		result["functions"] = functions
+
+		return result, nil
+	})
+}
@@ -0,0 +1,121 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// publicKeyFromPrivate derives the public key of a hex private key
+func publicKeyFromPrivate(privateKeyHex string) ([]byte, error) {
+	privateKeyBytes, err := decodePrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, signing.Errorf(errcodes.InvalidKey, "privateKey", err, "Invalid private key")
+	}
+	defer func() {
+		for i := range privateKeyBytes {
+			privateKeyBytes[i] = 0
+		}
+	}()
+
+	return signing.PublicKey(privateKeyBytes)
+}
+
+// publicKeyArg derives the public key for a key argument, which is either a
+// hex private key or an object holding an openSession handle.
+func publicKeyArg(v js.Value) ([]byte, error) {
+	if v.Type() == js.TypeString {
+		return publicKeyFromPrivate(v.String())
+	}
+
+	if v.Type() == js.TypeObject && v.Get("session").Type() == js.TypeString {
+		sess, err := sessionFromParams(v)
+		if err != nil {
+			return nil, err
+		}
+		return sess.PublicKey(), nil
+	}
+
+	return nil, fmt.Errorf("Missing arguments: privateKey or {session} required")
+}
+
+// decodePublicKey parses a hex public key with or without 0x prefix
+func decodePublicKey(publicKeyHex string) ([]byte, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(publicKeyHex, "0x"))
+	if err != nil || len(pubKey) != signing.KeySize {
+		return nil, signing.Errorf(errcodes.InvalidKey, "publicKey", nil, "Invalid public key: expected %d hex-encoded bytes", signing.KeySize)
+	}
+	return pubKey, nil
+}
+
+// getPublicKey resolves the 0x-prefixed public key of a private key:
+// getPublicKey(privateKey | {session})
+func getPublicKey(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("Missing arguments: privateKey required")
+		}
+
+		pubKey, err := publicKeyArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return "0x" + hex.EncodeToString(pubKey), nil
+	})
+}
+
+// validatePrivateKey checks that a hex string is a usable API private key.
+// Resolves {valid, publicKey} or {valid: false, error}.
+func validatePrivateKey(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: privateKey required")
+		}
+
+		pubKey, err := publicKeyFromPrivate(args[0].String())
+		if err != nil {
+			return map[string]interface{}{
+				"valid": false,
+				"error": err.Error(),
+			}, nil
+		}
+		return map[string]interface{}{
+			"valid":     true,
+			"publicKey": "0x" + hex.EncodeToString(pubKey),
+		}, nil
+	})
+}
+
+// comparePublicKey checks whether a private key belongs to a public key, e.g.
+// one listed by getAccountApiKeys: comparePublicKey(privateKey | {session},
+// publicKey). Resolves {match, publicKey}.
+func comparePublicKey(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 2 || args[1].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: privateKey and publicKey required")
+		}
+
+		pubKey, err := publicKeyArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		expected, err := decodePublicKey(args[1].String())
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"match":     bytes.Equal(pubKey, expected),
+			"publicKey": "0x" + hex.EncodeToString(pubKey),
+		}, nil
+	})
+}
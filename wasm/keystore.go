@@ -0,0 +1,116 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// encryptKeyOptions are the options of encryptKey. EncryptKey checks
+// ScryptN; zero selects signing.DefaultScryptN.
+type encryptKeyOptions struct {
+	ScryptN int64 `param:"scryptN,optional"`
+}
+
+// keystoreArg accepts a keystore as a JSON string or as the parsed object
+func keystoreArg(v js.Value) (string, error) {
+	switch v.Type() {
+	case js.TypeString:
+		return v.String(), nil
+	case js.TypeObject:
+		if !v.IsNull() {
+			return js.Global().Get("JSON").Call("stringify", v).String(), nil
+		}
+	}
+	return "", fmt.Errorf("Missing arguments: keystore required")
+}
+
+// encryptKey seals a private key under a password:
+// encryptKey(privateKey, password[, {scryptN}]). Resolves the keystore
+// object; store it with JSON.stringify.
+func encryptKey(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 2 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: privateKey and password required")
+		}
+		if args[1].String() == "" {
+			return nil, fmt.Errorf("Invalid password: must not be empty")
+		}
+
+		options := js.ValueOf(map[string]interface{}{})
+		if len(args) > 2 && !args[2].IsUndefined() {
+			options = args[2]
+		}
+		var opts encryptKeyOptions
+		if err := decodeParams(options, &opts); err != nil {
+			return nil, err
+		}
+		if opts.ScryptN == 0 {
+			opts.ScryptN = signing.DefaultScryptN
+		}
+
+		privateKey, err := decodePrivateKey(args[0].String())
+		if err != nil {
+			return nil, signing.Errorf(errcodes.InvalidKey, "privateKey", err, "Invalid private key")
+		}
+		defer func() {
+			for i := range privateKey {
+				privateKey[i] = 0
+			}
+		}()
+
+		ks, err := signing.EncryptKey(privateKey, []byte(args[1].String()), int(opts.ScryptN))
+		if err != nil {
+			return nil, err
+		}
+
+		blob, err := json.Marshal(ks)
+		if err != nil {
+			return nil, signing.Errorf(errcodes.MarshalFailed, "", err, "Failed to marshal JSON")
+		}
+		return js.Global().Get("JSON").Call("parse", string(blob)), nil
+	})
+}
+
+// decryptKey opens a keystore: decryptKey(keystore, password). Resolves
+// {privateKey, publicKey}. Prefer openSession({keystore, password, ...}),
+// which keeps the plaintext key inside Go.
+func decryptKey(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 2 || args[1].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: keystore and password required")
+		}
+
+		blob, err := keystoreArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err := signing.DecryptKey([]byte(blob), []byte(args[1].String()))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for i := range privateKey {
+				privateKey[i] = 0
+			}
+		}()
+
+		publicKey, err := publicKeyFromPrivate(hex.EncodeToString(privateKey))
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"privateKey": "0x" + hex.EncodeToString(privateKey),
+			"publicKey":  "0x" + hex.EncodeToString(publicKey),
+		}, nil
+	})
+}
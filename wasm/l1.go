@@ -0,0 +1,79 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"github.com/ethereum/go-ethereum/common"
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// attachL1Params takes at most one of a personal_sign signature made
+// elsewhere or the secp256k1 key to make it with, as signChangePubKey does
+type attachL1Params struct {
+	L1Address    string `param:"l1Address,optional"`
+	L1Signature  []byte `param:"l1Signature,optional,size=65"`
+	L1PrivateKey []byte `param:"l1PrivateKey,optional,size=32"`
+}
+
+// attachL1Signature adds the account owner's L1 signature to a signed
+// ChangePubKey tx: attachL1Signature(txInfo, {l1Signature | l1PrivateKey,
+// l1Address?}). With l1Address the signer must match it. Resolves {txType,
+// txInfo, l1Message, l1Address}.
+func attachL1Signature(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 2 || args[0].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: txInfo and L1 signature parameters required")
+		}
+
+		var p attachL1Params
+		if err := decodeParams(args[1], &p); err != nil {
+			return nil, err
+		}
+		if p.L1Signature == nil && p.L1PrivateKey == nil {
+			return nil, &paramError{Reason: reasonMissing, Field: "l1Signature", Message: "is required unless l1PrivateKey is given"}
+		}
+
+		parsed, sig, err := signing.ParseTx(txtypes.TxTypeL2ChangePubKey, args[0].String())
+		if err != nil {
+			return nil, err
+		}
+		tx := parsed.(*txtypes.L2ChangePubKeyTxInfo)
+		tx.Sig = sig
+
+		privateKey := p.L1PrivateKey
+		address, err := signing.AttachL1(tx, p.L1Signature, privateKey)
+		for i := range privateKey {
+			privateKey[i] = 0
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if expected := p.L1Address; expected != "" {
+			if !common.IsHexAddress(expected) {
+				return nil, &paramError{Reason: reasonType, Field: "l1Address", Message: "expected a hex Ethereum address"}
+			}
+			if common.HexToAddress(expected) != address {
+				return nil, signing.Errorf(errcodes.InvalidParam, "l1Address", nil, "L1 signature is from %s, expected %s", address.Hex(), common.HexToAddress(expected).Hex())
+			}
+		}
+
+		txInfo, err := tx.GetTxInfo()
+		if err != nil {
+			return nil, signing.Errorf(errcodes.MarshalFailed, "", err, "Failed to marshal JSON")
+		}
+
+		return map[string]interface{}{
+			"txType":    int(txtypes.TxTypeL2ChangePubKey),
+			"txInfo":    txInfo,
+			"l1Message": tx.GetL1SignatureBody(),
+			"l1Address": address.Hex(),
+		}, nil
+	})
+}
@@ -5,47 +5,83 @@ package main
 
 import (
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"syscall/js"
 	"time"
 
-	"github.com/elliottech/lighter-go/signer"
-	"github.com/elliottech/lighter-go/types"
-	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
-	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"lighter-wasm/signing"
 )
 
 func main() {
 	c := make(chan struct{})
-	
-	js.Global().Set("LighterWASM", js.ValueOf(map[string]interface{}{
-		"ready":                  js.ValueOf(true),
-		"generateKey":            js.FuncOf(generateKey),
-		"signCreateOrder":        js.FuncOf(signCreateOrder),
-		"signCancelOrder":        js.FuncOf(signCancelOrder),
-		"signModifyOrder":        js.FuncOf(signModifyOrder),
-		"signCancelAllOrders":    js.FuncOf(signCancelAllOrders),
+
+	// Refuse to report ready if the linked crypto does not reproduce the
+	// known answers; selfTest stays available to explain why
+	selfTestResults = runSelfTest()
+	ready := selfTestPassed(selfTestResults)
+
+	exports := map[string]interface{}{
+		"ready":                   js.ValueOf(ready),
+		"info":                    js.FuncOf(info),
+		"selfTest":                js.FuncOf(selfTest),
+		"generateKey":             js.FuncOf(generateKey),
+		"deriveKeyFromSeed":       js.FuncOf(deriveKeyFromSeed),
+		"keyDerivationMessage":    keyDerivationMessage,
+		"errorCodes":              errorCatalog(),
+		"getPublicKey":            js.FuncOf(getPublicKey),
+		"validatePrivateKey":      js.FuncOf(validatePrivateKey),
+		"comparePublicKey":        js.FuncOf(comparePublicKey),
+		"encryptKey":              js.FuncOf(encryptKey),
+		"decryptKey":              js.FuncOf(decryptKey),
+		"openSession":             js.FuncOf(openSession),
+		"closeSession":            js.FuncOf(closeSession),
+		"seedNonce":               js.FuncOf(seedNonce),
+		"reserveNonce":            js.FuncOf(reserveNonce),
+		"commitNonce":             js.FuncOf(commitNonce),
+		"rollbackNonce":           js.FuncOf(rollbackNonce),
+		"resyncNonce":             js.FuncOf(resyncNonce),
+		"nonceStatus":             js.FuncOf(nonceStatus),
+		"signTx":                  js.FuncOf(signTx),
+		"signBatch":               js.FuncOf(signBatch),
+		"signCreateOrder":         js.FuncOf(signCreateOrder),
+		"signCancelOrder":         js.FuncOf(signCancelOrder),
+		"signModifyOrder":         js.FuncOf(signModifyOrder),
+		"signCancelAllOrders":     js.FuncOf(signCancelAllOrders),
 		"signCreateGroupedOrders": js.FuncOf(signCreateGroupedOrders),
-		"signUpdateLeverage":     js.FuncOf(signUpdateLeverage),
-		"signUpdateMargin":       js.FuncOf(signUpdateMargin),
-		"signWithdraw":           js.FuncOf(signWithdraw),
-		"signTransfer":           js.FuncOf(signTransfer),
-		"signCreateSubAccount":   js.FuncOf(signCreateSubAccount),
-		"signChangePubKey":       js.FuncOf(signChangePubKey),
-		"signCreatePublicPool":   js.FuncOf(signCreatePublicPool),
-		"signUpdatePublicPool":   js.FuncOf(signUpdatePublicPool),
-		"signMintShares":         js.FuncOf(signMintShares),
-		"signBurnShares":         js.FuncOf(signBurnShares),
-		"createAuthToken":        js.FuncOf(createAuthToken),
-	}))
-	
-	println("✅ Lighter WASM Signer Ready!")
+		"signUpdateLeverage":      js.FuncOf(signUpdateLeverage),
+		"signUpdateMargin":        js.FuncOf(signUpdateMargin),
+		"signWithdraw":            js.FuncOf(signWithdraw),
+		"signTransfer":            js.FuncOf(signTransfer),
+		"signCreateSubAccount":    js.FuncOf(signCreateSubAccount),
+		"signChangePubKey":        js.FuncOf(signChangePubKey),
+		"attachL1Signature":       js.FuncOf(attachL1Signature),
+		"signCreatePublicPool":    js.FuncOf(signCreatePublicPool),
+		"signUpdatePublicPool":    js.FuncOf(signUpdatePublicPool),
+		"signMintShares":          js.FuncOf(signMintShares),
+		"signBurnShares":          js.FuncOf(signBurnShares),
+		"createAuthToken":         js.FuncOf(createAuthToken),
+		"decodeTx":                js.FuncOf(decodeTx),
+		"verifyTx":                js.FuncOf(verifyTx),
+		"sync":                    syncExports(),
+	}
+	exportedFunctions = functionNames(exports, "")
+	js.Global().Set("LighterWASM", js.ValueOf(exports))
+
+	if ready {
+		println("✅ Lighter WASM Signer Ready!")
+	}
+	for _, r := range selfTestResults {
+		if r.err != nil {
+			println("❌ Lighter WASM self-test failed:", r.name+":", r.err.Error())
+		}
+	}
 	<-c
 }
 
-// generateKey generates a new API key pair
-func generateKey(this js.Value, args []js.Value) interface{} {
+// newPromise runs fn on its own goroutine and settles a JS promise with its
+// result. Errors are rejected through rejectError, panics as "Panic: ...".
+func newPromise(fn func() (interface{}, error)) js.Value {
 	handler := js.FuncOf(func(this js.Value, promiseArgs []js.Value) interface{} {
 		resolve := promiseArgs[0]
 		reject := promiseArgs[1]
@@ -53,18 +89,16 @@ func generateKey(this js.Value, args []js.Value) interface{} {
 		go func() {
 			defer func() {
 				if r := recover(); r != nil {
-					reject.Invoke(fmt.Sprintf("Panic: %v", r))
+					rejectError(reject, panicError(r))
 				}
 			}()
 
-			key := curve.SampleScalar(nil)
-			pk := schnorr.SchnorrPkFromSk(key)
-			
-			result := map[string]interface{}{
-				"privateKey": "0x" + hex.EncodeToString(key.ToLittleEndianBytes()),
-				"publicKey":  "0x" + hex.EncodeToString(pk.ToLittleEndianBytes()),
+			result, err := fn()
+			if err != nil {
+				rejectError(reject, err)
+				return
 			}
-			
+
 			resolve.Invoke(js.ValueOf(result))
 		}()
 
@@ -75,241 +109,50 @@ func generateKey(this js.Value, args []js.Value) interface{} {
 	return promiseConstructor.New(handler)
 }
 
-// signCreateOrder signs a create order transaction
-func signCreateOrder(this js.Value, args []js.Value) interface{} {
-	handler := js.FuncOf(func(this js.Value, promiseArgs []js.Value) interface{} {
-		resolve := promiseArgs[0]
-		reject := promiseArgs[1]
-
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					reject.Invoke(fmt.Sprintf("Panic: %v", r))
-				}
-			}()
-
-			if len(args) < 1 {
-				reject.Invoke("Missing arguments: no parameters provided")
-				return
-			}
-
-			params := args[0]
-			
-			// Extract parameters
-			privateKeyHex := params.Get("privateKey").String()
-			if len(privateKeyHex) > 2 && privateKeyHex[:2] == "0x" {
-				privateKeyHex = privateKeyHex[2:]
-			}
-			
-			privateKeyBytes, err := hex.DecodeString(privateKeyHex)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Invalid private key: %v", err))
-				return
-			}
-
-			// Create signer
-			keyManager, err := signer.NewKeyManager(privateKeyBytes)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to create key manager: %v", err))
-				return
-			}
-
-			// Build transaction
-			chainId := uint32(params.Get("chainId").Int())
-			accountIndex := int64(params.Get("accountIndex").Int())
-			apiKeyIndex := uint8(params.Get("apiKeyIndex").Int())
-			nonce := int64(params.Get("nonce").Int())
-			expiredAt := params.Get("expiredAt").Int()
-			
-			if expiredAt == 0 {
-				expiredAt = int(time.Now().Add(10 * time.Minute).UnixMilli())
-			}
-
-			orderReq := &types.CreateOrderTxReq{
-				MarketIndex:      uint8(params.Get("marketIndex").Int()),
-				ClientOrderIndex: int64(params.Get("clientOrderIndex").Int()),
-				BaseAmount:       int64(params.Get("baseAmount").Int()),
-				Price:            uint32(params.Get("price").Int()),
-				IsAsk:            uint8(params.Get("isAsk").Int()),
-				Type:             uint8(params.Get("orderType").Int()),
-				TimeInForce:      uint8(params.Get("timeInForce").Int()),
-				ReduceOnly:       uint8(params.Get("reduceOnly").Int()),
-				TriggerPrice:     uint32(params.Get("triggerPrice").Int()),
-				OrderExpiry:      int64(params.Get("orderExpiry").Int()),
-			}
-
-			ops := &types.TransactOpts{
-				FromAccountIndex: &accountIndex,
-				ApiKeyIndex:      &apiKeyIndex,
-				Nonce:            &nonce,
-				ExpiredAt:        int64(expiredAt),
-			}
-
-			// Sign the order
-			signedTx, err := types.ConstructCreateOrderTx(keyManager, chainId, orderReq, ops)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to sign order: %v", err))
-				return
-			}
-
-			// Convert to JSON
-			txJSON, err := json.Marshal(signedTx)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to marshal JSON: %v", err))
-				return
-			}
-
-			resolve.Invoke(string(txJSON))
-		}()
+// generateKey generates a new API key pair
+func generateKey(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		privateKey, publicKey := signing.GenerateKey()
 
-		return nil
+		return map[string]interface{}{
+			"privateKey": "0x" + hex.EncodeToString(privateKey),
+			"publicKey":  "0x" + hex.EncodeToString(publicKey),
+		}, nil
 	})
+}
 
-	promiseConstructor := js.Global().Get("Promise")
-	return promiseConstructor.New(handler)
+// signCreateOrder signs a create order transaction
+func signCreateOrder(this js.Value, args []js.Value) interface{} {
+	return signExport(txtypes.TxTypeL2CreateOrder, args)
 }
 
 // signCancelOrder signs a cancel order transaction
 func signCancelOrder(this js.Value, args []js.Value) interface{} {
-	handler := js.FuncOf(func(this js.Value, promiseArgs []js.Value) interface{} {
-		resolve := promiseArgs[0]
-		reject := promiseArgs[1]
-
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					reject.Invoke(fmt.Sprintf("Panic: %v", r))
-				}
-			}()
-
-			if len(args) < 1 {
-				reject.Invoke("Missing arguments: no parameters provided")
-				return
-			}
-
-			params := args[0]
-			
-			privateKeyHex := params.Get("privateKey").String()
-			if len(privateKeyHex) > 2 && privateKeyHex[:2] == "0x" {
-				privateKeyHex = privateKeyHex[2:]
-			}
-			
-			privateKeyBytes, err := hex.DecodeString(privateKeyHex)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Invalid private key: %v", err))
-				return
-			}
-
-			keyManager, err := signer.NewKeyManager(privateKeyBytes)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to create key manager: %v", err))
-				return
-			}
-
-			chainId := uint32(params.Get("chainId").Int())
-			accountIndex := int64(params.Get("accountIndex").Int())
-			apiKeyIndex := uint8(params.Get("apiKeyIndex").Int())
-			nonce := int64(params.Get("nonce").Int())
-			expiredAt := int64(time.Now().Add(10 * time.Minute).UnixMilli())
-
-			cancelReq := &types.CancelOrderTxReq{
-				MarketIndex: uint8(params.Get("marketIndex").Int()),
-				Index:       int64(params.Get("orderIndex").Int()),
-			}
-
-			ops := &types.TransactOpts{
-				FromAccountIndex: &accountIndex,
-				ApiKeyIndex:      &apiKeyIndex,
-				Nonce:            &nonce,
-				ExpiredAt:        expiredAt,
-			}
-
-			signedTx, err := types.ConstructL2CancelOrderTx(keyManager, chainId, cancelReq, ops)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to sign cancel: %v", err))
-				return
-			}
-
-			txJSON, err := json.Marshal(signedTx)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to marshal JSON: %v", err))
-				return
-			}
-
-			resolve.Invoke(string(txJSON))
-		}()
-
-		return nil
-	})
-
-	promiseConstructor := js.Global().Get("Promise")
-	return promiseConstructor.New(handler)
+	return signExport(txtypes.TxTypeL2CancelOrder, args)
 }
 
 // createAuthToken creates an authentication token
 func createAuthToken(this js.Value, args []js.Value) interface{} {
-	handler := js.FuncOf(func(this js.Value, promiseArgs []js.Value) interface{} {
-		resolve := promiseArgs[0]
-		reject := promiseArgs[1]
-
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					reject.Invoke(fmt.Sprintf("Panic: %v", r))
-				}
-			}()
-
-			if len(args) < 1 {
-				reject.Invoke("Missing arguments: no parameters provided")
-				return
-			}
-
-			params := args[0]
-			
-			privateKeyHex := params.Get("privateKey").String()
-			if len(privateKeyHex) > 2 && privateKeyHex[:2] == "0x" {
-				privateKeyHex = privateKeyHex[2:]
-			}
-			
-			privateKeyBytes, err := hex.DecodeString(privateKeyHex)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Invalid private key: %v", err))
-				return
-			}
-
-			keyManager, err := signer.NewKeyManager(privateKeyBytes)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to create key manager: %v", err))
-				return
-			}
-
-			accountIndex := int64(params.Get("accountIndex").Int())
-			apiKeyIndex := uint8(params.Get("apiKeyIndex").Int())
-			expiryHours := params.Get("expiryHours").Int()
-			
-			if expiryHours == 0 {
-				expiryHours = 8
-			}
-			
-			deadline := time.Now().Add(time.Duration(expiryHours) * time.Hour)
-
-			ops := &types.TransactOpts{
-				FromAccountIndex: &accountIndex,
-				ApiKeyIndex:      &apiKeyIndex,
-			}
+	return newPromise(func() (interface{}, error) {
+		return createAuthTokenArgs(args)
+	})
+}
 
-			authToken, err := types.ConstructAuthToken(keyManager, deadline, ops)
-			if err != nil {
-				reject.Invoke(fmt.Sprintf("Failed to create auth token: %v", err))
-				return
-			}
+func createAuthTokenArgs(args []js.Value) (interface{}, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("Missing arguments: no parameters provided")
+	}
 
-			resolve.Invoke(authToken)
-		}()
+	params := args[0]
 
-		return nil
-	})
+	var req signing.AuthToken
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
 
-	promiseConstructor := js.Global().Get("Promise")
-	return promiseConstructor.New(handler)
+	sess, err := sessionFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return sess.AuthToken(req, time.Now())
 }
@@ -0,0 +1,500 @@
+package mock
+
+import (
+	"sort"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"lighter-wasm/api"
+)
+
+// twapInterval is the time between the slices of a TWAP order. A TWAP
+// runs until its OrderExpiry, in at least one slice.
+const twapInterval = 30 * time.Second
+
+// recentTrades bounds the trades kept per market and account
+const recentTrades = 100
+
+// level is a price on one side of a book
+type level struct {
+	isAsk bool
+	price int64
+}
+
+// changes collects what one tx, or one Advance, touched so that its stream
+// messages go out together
+type changes struct {
+	now    time.Time
+	txHash string
+
+	// accounts lists the touched accounts in the order first touched
+	accounts  []*account
+	orders    map[*account][]*order
+	trades    map[*account][]api.Trade
+	positions map[*account]map[*market]bool
+
+	markets     []*market
+	levels      map[*market]map[level]bool
+	tradesByMkt map[*market][]api.Trade
+
+	// activate holds children released by a parent, placed once the
+	// current order is done matching
+	activate []*order
+}
+
+func newChanges(now time.Time, txHash string) *changes {
+	return &changes{
+		now:         now,
+		txHash:      txHash,
+		orders:      make(map[*account][]*order),
+		trades:      make(map[*account][]api.Trade),
+		positions:   make(map[*account]map[*market]bool),
+		levels:      make(map[*market]map[level]bool),
+		tradesByMkt: make(map[*market][]api.Trade),
+	}
+}
+
+func (ch *changes) account(acc *account) {
+	for _, a := range ch.accounts {
+		if a == acc {
+			return
+		}
+	}
+	ch.accounts = append(ch.accounts, acc)
+}
+
+func (ch *changes) market(m *market) {
+	for _, mm := range ch.markets {
+		if mm == m {
+			return
+		}
+	}
+	ch.markets = append(ch.markets, m)
+}
+
+func (ch *changes) order(acc *account, o *order) {
+	ch.account(acc)
+	for _, seen := range ch.orders[acc] {
+		if seen == o {
+			return
+		}
+	}
+	ch.orders[acc] = append(ch.orders[acc], o)
+}
+
+func (ch *changes) position(acc *account, m *market) {
+	ch.account(acc)
+	if ch.positions[acc] == nil {
+		ch.positions[acc] = make(map[*market]bool)
+	}
+	ch.positions[acc][m] = true
+}
+
+func (ch *changes) level(m *market, isAsk bool, price int64) {
+	ch.market(m)
+	if ch.levels[m] == nil {
+		ch.levels[m] = make(map[level]bool)
+	}
+	ch.levels[m][level{isAsk, price}] = true
+}
+
+// side returns the resting orders of one side, best price first and
+// oldest first within a price
+func (m *market) side(isAsk bool) *[]*order {
+	if isAsk {
+		return &m.asks
+	}
+	return &m.bids
+}
+
+// rest queues o behind the resting orders at its price
+func (m *market) rest(o *order) {
+	side := m.side(o.IsAsk)
+	i := sort.Search(len(*side), func(i int) bool {
+		if o.IsAsk {
+			return (*side)[i].price > o.price
+		}
+		return (*side)[i].price < o.price
+	})
+	*side = append(*side, nil)
+	copy((*side)[i+1:], (*side)[i:])
+	(*side)[i] = o
+}
+
+// remove deletes o from list, reporting whether it was there
+func remove(list *[]*order, o *order) bool {
+	for i, x := range *list {
+		if x == o {
+			*list = append((*list)[:i], (*list)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// levelSize sums the resting size at one price
+func (m *market) levelSize(l level) int64 {
+	var size int64
+	for _, o := range *m.side(l.isAsk) {
+		if o.price == l.price {
+			size += o.remaining
+		}
+	}
+	return size
+}
+
+// crosses reports whether o would take liquidity at its price
+func (m *market) crosses(o *order) bool {
+	book := *m.side(!o.IsAsk)
+	if len(book) == 0 {
+		return false
+	}
+	if o.IsAsk {
+		return book[0].price >= o.price
+	}
+	return book[0].price <= o.price
+}
+
+// place runs a new, triggered or released order through the engine by
+// type and time in force; mu must be held
+func (s *Server) place(o *order, ch *changes) {
+	m := s.markets[o.MarketIndex]
+	acc := s.accounts[o.OwnerAccountIndex]
+	ch.order(acc, o)
+
+	// A reduce-only trigger order is checked when it fires, since the
+	// position it protects may not be open yet
+	if o.isTrigger() && o.TriggerTime == 0 {
+		o.Status = statusPending
+		o.TriggerStatus = "mark-price"
+		m.triggers = append(m.triggers, o)
+		return
+	}
+	if o.ReduceOnly && acc.reducible(o) == 0 {
+		s.closeOrder(o, statusCanceledReduceOnly, ch)
+		return
+	}
+	o.Status = statusOpen
+	if o.kind == orderTwap {
+		o.TriggerStatus = "twap"
+		o.twapStart = ch.now
+		m.twaps = append(m.twaps, o)
+		s.runTwap(o, ch)
+		return
+	}
+
+	if o.tif == tifPostOnly && m.crosses(o) {
+		s.closeOrder(o, statusCanceledPostOnly, ch)
+		return
+	}
+	s.match(m, o, o.remaining, ch)
+	switch {
+	case o.remaining == 0:
+		s.closeOrder(o, statusFilled, ch)
+	case o.isMarket():
+		s.closeOrder(o, statusCanceledLiquidity, ch)
+	case o.tif == tifImmediateOrCancel:
+		s.closeOrder(o, statusCanceled, ch)
+	default:
+		m.rest(o)
+		ch.level(m, o.IsAsk, o.price)
+	}
+}
+
+// match fills o against the other side of its market, best price first,
+// for at most max base units. o itself is left for the caller to close.
+func (s *Server) match(m *market, o *order, max int64, ch *changes) {
+	acc := s.accounts[o.OwnerAccountIndex]
+	book := m.side(!o.IsAsk)
+	for max > 0 && o.remaining > 0 && len(*book) > 0 {
+		maker := (*book)[0]
+		if o.IsAsk && maker.price < o.price || !o.IsAsk && maker.price > o.price {
+			return
+		}
+		makerAcc := s.accounts[maker.OwnerAccountIndex]
+		if makerAcc == acc {
+			s.closeOrder(maker, statusCanceledSelfTrade, ch)
+			continue
+		}
+
+		qty := min(max, o.remaining, maker.remaining)
+		if o.ReduceOnly {
+			if qty = min(qty, acc.reducible(o)); qty == 0 {
+				return
+			}
+		}
+		if maker.ReduceOnly {
+			reducible := makerAcc.reducible(maker)
+			if reducible == 0 {
+				s.closeOrder(maker, statusCanceledReduceOnly, ch)
+				continue
+			}
+			qty = min(qty, reducible)
+		}
+		s.fill(m, maker, o, qty, ch)
+		max -= qty
+	}
+}
+
+// fill trades qty between a resting maker and a taker at the maker's price
+func (s *Server) fill(m *market, maker, taker *order, qty int64, ch *changes) {
+	price := maker.price
+	for _, o := range []*order{maker, taker} {
+		acc := s.accounts[o.OwnerAccountIndex]
+		o.filled += qty
+		o.remaining -= qty
+		o.quote += qty * price
+		s.refresh(o)
+		acc.position(m.detail.MarketId).apply(o.IsAsk, qty, price)
+		ch.order(acc, o)
+		ch.position(acc, m)
+	}
+	ch.level(m, maker.IsAsk, price)
+	m.mark = price
+
+	ask, bid := maker, taker
+	if taker.IsAsk {
+		ask, bid = taker, maker
+	}
+	trade := api.Trade{
+		TradeId:      s.nextTrade,
+		TxHash:       ch.txHash,
+		Type:         "trade",
+		MarketId:     m.detail.MarketId,
+		Size:         m.format.FormatSize(qty),
+		Price:        m.format.FormatPrice(price),
+		UsdAmount:    quoteString(qty*price, m.format.PriceDecimals+m.format.SizeDecimals),
+		AskId:        ask.OrderIndex,
+		BidId:        bid.OrderIndex,
+		AskAccountId: ask.OwnerAccountIndex,
+		BidAccountId: bid.OwnerAccountIndex,
+		IsMakerAsk:   maker.IsAsk,
+		BlockHeight:  int64(len(s.txs)),
+		Timestamp:    ch.now.UnixMilli(),
+	}
+	s.nextTrade++
+	m.trades = appendRecent(m.trades, trade)
+	ch.tradesByMkt[m] = append(ch.tradesByMkt[m], trade)
+	for _, index := range []int64{ask.OwnerAccountIndex, bid.OwnerAccountIndex} {
+		acc := s.accounts[index]
+		acc.trades = appendRecent(acc.trades, trade)
+		ch.trades[acc] = append(ch.trades[acc], trade)
+	}
+
+	for _, o := range []*order{maker, taker} {
+		if other := o.oco; other != nil {
+			o.oco, other.oco = nil, nil
+			s.closeOrder(other, statusCanceledOCO, ch)
+		}
+	}
+	if maker.remaining == 0 {
+		s.closeOrder(maker, statusFilled, ch)
+	}
+}
+
+func appendRecent(trades []api.Trade, t api.Trade) []api.Trade {
+	trades = append(trades, t)
+	if len(trades) > recentTrades {
+		trades = trades[len(trades)-recentTrades:]
+	}
+	return trades
+}
+
+// closeOrder takes o out of the engine and closes it with status. The
+// children of a parent that filled are released with the filled size;
+// those of one that did not are canceled.
+func (s *Server) closeOrder(o *order, status string, ch *changes) {
+	m := s.markets[o.MarketIndex]
+	acc := s.accounts[o.OwnerAccountIndex]
+	if _, ok := acc.orders[o.OrderIndex]; !ok {
+		return
+	}
+	if remove(m.side(o.IsAsk), o) {
+		ch.level(m, o.IsAsk, o.price)
+	}
+	remove(&m.triggers, o)
+	remove(&m.twaps, o)
+	if o.oco != nil {
+		o.oco.oco = nil
+		o.oco = nil
+	}
+	acc.close(o, status, ch.now)
+	ch.order(acc, o)
+
+	children := o.children
+	o.children = nil
+	for _, child := range children {
+		if _, ok := acc.orders[child.OrderIndex]; !ok {
+			continue
+		}
+		if o.filled == 0 {
+			s.closeOrder(child, statusCanceled, ch)
+			continue
+		}
+		if child.base == 0 || child.base > o.filled {
+			child.base = o.filled
+			child.remaining = o.filled
+			child.BaseSize = o.filled
+			s.refresh(child)
+		}
+		child.TriggerStatus = "na"
+		ch.activate = append(ch.activate, child)
+	}
+}
+
+// group links the orders of a CreateGroupedOrders tx and places them. The
+// children of a triggering parent wait with trigger status parent-order.
+func (s *Server) group(groupingType uint8, orders []*order, ch *changes) {
+	var parent *order
+	switch {
+	case groupingType == groupOneTriggersOther && len(orders) >= 2,
+		groupingType == groupOneTriggersOCO && len(orders) == 3:
+		parent, orders = orders[0], orders[1:]
+		parent.children = orders
+		for i, child := range orders {
+			child.TriggerStatus = "parent-order"
+			child.ParentOrderIndex = parent.OrderIndex
+			child.ParentOrderId = parent.OrderId
+			if i == 0 {
+				parent.ToTriggerOrderId0 = child.OrderId
+			} else {
+				parent.ToTriggerOrderId1 = child.OrderId
+			}
+			ch.order(s.accounts[child.OwnerAccountIndex], child)
+		}
+	}
+	if (groupingType == groupOneCancelsOther || groupingType == groupOneTriggersOCO && parent != nil) && len(orders) == 2 {
+		orders[0].oco, orders[1].oco = orders[1], orders[0]
+		orders[0].ToCancelOrderId0 = orders[1].OrderId
+		orders[1].ToCancelOrderId0 = orders[0].OrderId
+	}
+
+	if parent != nil {
+		s.place(parent, ch)
+		return
+	}
+	for _, o := range orders {
+		s.place(o, ch)
+	}
+}
+
+// modify applies a ModifyOrder. A resting order loses its time priority
+// and may cross; a waiting trigger order just takes the new prices.
+func (s *Server) modify(o *order, tx *txtypes.L2ModifyOrderTxInfo, accepted Tx, ch *changes) {
+	m := s.markets[o.MarketIndex]
+	resting := remove(m.side(o.IsAsk), o)
+	if resting {
+		ch.level(m, o.IsAsk, o.price)
+	}
+	s.modifyOrder(o, tx, accepted, ch.now)
+	ch.order(s.accounts[o.OwnerAccountIndex], o)
+
+	switch {
+	case o.remaining == 0:
+		s.closeOrder(o, statusFilled, ch)
+	case resting:
+		s.place(o, ch)
+	}
+}
+
+// triggered reports whether a trigger order fires at mark. A sell stop
+// loss and a buy take profit fire at or below their trigger price, the
+// others at or above.
+func (o *order) triggered(mark int64) bool {
+	stop := o.kind == orderStopLoss || o.kind == orderStopLossLimit
+	if o.IsAsk == stop {
+		return mark <= o.trigger
+	}
+	return mark >= o.trigger
+}
+
+// settle places released children and fires trigger orders until neither
+// is left, as each can trade and move the mark price
+func (s *Server) settle(ch *changes) {
+	for {
+		if len(ch.activate) > 0 {
+			o := ch.activate[0]
+			ch.activate = ch.activate[1:]
+			s.place(o, ch)
+			continue
+		}
+		if !s.fireTrigger(ch) {
+			return
+		}
+	}
+}
+
+// fireTrigger executes the first trigger order its market's mark price
+// has reached, reporting whether there was one
+func (s *Server) fireTrigger(ch *changes) bool {
+	for _, m := range s.sortedMarkets() {
+		if m.mark == 0 {
+			continue
+		}
+		for _, o := range m.triggers {
+			if !o.triggered(m.mark) {
+				continue
+			}
+			remove(&m.triggers, o)
+			o.TriggerStatus = "na"
+			o.TriggerTime = ch.now.UnixMilli()
+			s.place(o, ch)
+			return true
+		}
+	}
+	return false
+}
+
+// runTwap executes the slices of a TWAP that are due. Each slice buys or
+// sells up to the pro-rata target, catching up on slices the book could
+// not fill, at o.price or better.
+func (s *Server) runTwap(o *order, ch *changes) {
+	m := s.markets[o.MarketIndex]
+	slices := 1
+	if o.OrderExpiry > 0 {
+		if n := int(time.UnixMilli(o.OrderExpiry).Sub(o.twapStart) / twapInterval); n > 1 {
+			slices = n
+		}
+	}
+
+	for o.twapDone < slices && o.remaining > 0 {
+		due := o.twapStart.Add(time.Duration(o.twapDone) * twapInterval)
+		if ch.now.Before(due) {
+			return
+		}
+		o.twapDone++
+		target := o.base * int64(o.twapDone) / int64(slices)
+		if want := target - o.filled; want > 0 {
+			s.match(m, o, want, ch)
+		}
+	}
+
+	if o.remaining == 0 {
+		s.closeOrder(o, statusFilled, ch)
+	} else {
+		s.closeOrder(o, statusCanceledLiquidity, ch)
+	}
+}
+
+// advance runs TWAP slices, scheduled cancel-alls and expires orders that
+// are due; mu must be held
+func (s *Server) advance(ch *changes) {
+	for _, m := range s.sortedMarkets() {
+		for _, o := range append([]*order(nil), m.twaps...) {
+			s.runTwap(o, ch)
+		}
+	}
+	nowMs := ch.now.UnixMilli()
+	for _, acc := range s.sortedAccounts() {
+		if acc.cancelAt > 0 && nowMs >= acc.cancelAt {
+			acc.cancelAt = 0
+			s.cancelAll(acc, ch)
+		}
+		for _, o := range acc.sortedOrders() {
+			if o.kind != orderTwap && o.OrderExpiry > 0 && nowMs >= o.OrderExpiry {
+				s.closeOrder(o, statusCanceledExpired, ch)
+			}
+		}
+	}
+	s.settle(ch)
+}
@@ -0,0 +1,505 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"lighter-wasm/api"
+)
+
+// testOrder is an order to run through the engine of a test server
+type testOrder struct {
+	account    int64
+	isAsk      bool
+	kind       uint8
+	tif        uint8
+	price      int64
+	size       int64
+	reduceOnly bool
+	trigger    int64
+	// expiry is the OrderExpiry in milliseconds
+	expiry int64
+}
+
+func bid(account, price, size int64) testOrder {
+	return testOrder{account: account, tif: tifGoodTillTime, price: price, size: size}
+}
+
+func ask(account, price, size int64) testOrder {
+	return testOrder{account: account, isAsk: true, tif: tifGoodTillTime, price: price, size: size}
+}
+
+func (t testOrder) with(kind, tif uint8) testOrder {
+	t.kind, t.tif = kind, tif
+	return t
+}
+
+func (t testOrder) triggerAt(kind, tif uint8, trigger int64) testOrder {
+	t.kind, t.tif, t.trigger = kind, tif, trigger
+	return t
+}
+
+func (t testOrder) reduce() testOrder {
+	t.reduceOnly = true
+	return t
+}
+
+// newTestServer lists market 0 without decimals and accounts 1 to 3
+func newTestServer() *Server {
+	s := NewServer(api.TestnetChainId)
+	s.AddMarket(api.OrderBookDetail{OrderBook: api.OrderBook{Symbol: "ETH", MarketId: 0}})
+	for i := int64(1); i <= 3; i++ {
+		s.AddAccount(i, "")
+	}
+	return s
+}
+
+func (s *Server) newTestOrder(t testOrder) *order {
+	info := &txtypes.OrderInfo{
+		MarketIndex:      0,
+		ClientOrderIndex: s.nextOrder,
+		BaseAmount:       t.size,
+		Price:            uint32(t.price),
+		Type:             t.kind,
+		TimeInForce:      t.tif,
+		TriggerPrice:     uint32(t.trigger),
+		OrderExpiry:      t.expiry,
+	}
+	if t.isAsk {
+		info.IsAsk = 1
+	}
+	if t.reduceOnly {
+		info.ReduceOnly = 1
+	}
+	return s.newOrder(s.accounts[t.account], info, Tx{}, s.now())
+}
+
+// placeTest places each order in its own tx
+func (s *Server) placeTest(orders ...testOrder) []*order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*order, len(orders))
+	for i, t := range orders {
+		ch := newChanges(s.now(), "")
+		out[i] = s.newTestOrder(t)
+		s.place(out[i], ch)
+		s.settle(ch)
+	}
+	return out
+}
+
+// groupTest places orders as one CreateGroupedOrders tx
+func (s *Server) groupTest(groupingType uint8, orders ...testOrder) []*order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*order, len(orders))
+	for i, t := range orders {
+		out[i] = s.newTestOrder(t)
+	}
+	ch := newChanges(s.now(), "")
+	s.group(groupingType, append([]*order(nil), out...), ch)
+	s.settle(ch)
+	return out
+}
+
+func (s *Server) positionSize(account int64) int64 {
+	if p, ok := s.accounts[account].positions[0]; ok {
+		return p.size
+	}
+	return 0
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name string
+		// book is placed first, then taker
+		book       []testOrder
+		taker      testOrder
+		status     string
+		filled     int64
+		bookStatus []string
+		// positions are the sizes of accounts 1 to 3
+		positions [3]int64
+	}{
+		{
+			name:   "rests on an empty book",
+			taker:  bid(1, 100, 5),
+			status: statusOpen,
+		},
+		{
+			name:       "fills at the maker price",
+			book:       []testOrder{ask(2, 99, 5)},
+			taker:      bid(1, 100, 5),
+			status:     statusFilled,
+			filled:     5,
+			bookStatus: []string{statusFilled},
+			positions:  [3]int64{5, -5, 0},
+		},
+		{
+			name:       "rests what does not fill",
+			book:       []testOrder{ask(2, 100, 3)},
+			taker:      bid(1, 100, 5),
+			status:     statusOpen,
+			filled:     3,
+			bookStatus: []string{statusFilled},
+			positions:  [3]int64{3, -3, 0},
+		},
+		{
+			name:       "stops at its limit price",
+			book:       []testOrder{ask(2, 101, 2)},
+			taker:      bid(1, 100, 2),
+			status:     statusOpen,
+			bookStatus: []string{statusOpen},
+		},
+		{
+			name:       "takes the best price, then the oldest order",
+			book:       []testOrder{ask(2, 101, 2), ask(2, 100, 2), ask(3, 100, 2)},
+			taker:      bid(1, 101, 3),
+			status:     statusFilled,
+			filled:     3,
+			bookStatus: []string{statusOpen, statusFilled, statusOpen},
+			positions:  [3]int64{3, -2, -1},
+		},
+		{
+			name:       "market order cancels the unfilled rest",
+			book:       []testOrder{ask(2, 100, 2)},
+			taker:      bid(1, 105, 5).with(orderMarket, tifImmediateOrCancel),
+			status:     statusCanceledLiquidity,
+			filled:     2,
+			bookStatus: []string{statusFilled},
+			positions:  [3]int64{2, -2, 0},
+		},
+		{
+			name:       "immediate or cancel does not rest",
+			book:       []testOrder{ask(2, 100, 2)},
+			taker:      bid(1, 100, 5).with(orderLimit, tifImmediateOrCancel),
+			status:     statusCanceled,
+			filled:     2,
+			bookStatus: []string{statusFilled},
+			positions:  [3]int64{2, -2, 0},
+		},
+		{
+			name:       "post only is canceled when it crosses",
+			book:       []testOrder{ask(2, 100, 2)},
+			taker:      bid(1, 100, 2).with(orderLimit, tifPostOnly),
+			status:     statusCanceledPostOnly,
+			bookStatus: []string{statusOpen},
+		},
+		{
+			name:       "post only rests when it does not cross",
+			book:       []testOrder{ask(2, 100, 2)},
+			taker:      bid(1, 99, 2).with(orderLimit, tifPostOnly),
+			status:     statusOpen,
+			bookStatus: []string{statusOpen},
+		},
+		{
+			name:       "self trade cancels the resting order",
+			book:       []testOrder{ask(1, 100, 2), ask(2, 100, 2)},
+			taker:      bid(1, 100, 2),
+			status:     statusFilled,
+			filled:     2,
+			bookStatus: []string{statusCanceledSelfTrade, statusFilled},
+			positions:  [3]int64{2, -2, 0},
+		},
+		{
+			name:   "reduce only without a position",
+			taker:  testOrder{account: 1, isAsk: true, tif: tifGoodTillTime, price: 100, size: 2, reduceOnly: true},
+			status: statusCanceledReduceOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			book := s.placeTest(tt.book...)
+			taker := s.placeTest(tt.taker)[0]
+
+			if taker.Status != tt.status || taker.filled != tt.filled {
+				t.Errorf("taker %s filled %d, want %s filled %d", taker.Status, taker.filled, tt.status, tt.filled)
+			}
+			for i, o := range book {
+				if o.Status != tt.bookStatus[i] {
+					t.Errorf("book order %d %s, want %s", i, o.Status, tt.bookStatus[i])
+				}
+			}
+			for i, want := range tt.positions {
+				if got := s.positionSize(int64(i + 1)); got != want {
+					t.Errorf("account %d position %d, want %d", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCloseOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		book     []testOrder
+		grouping uint8
+		group    []testOrder
+		// after is placed once the group is
+		after     []testOrder
+		status    []string
+		remaining []int64
+	}{
+		{
+			name:      "filled parent releases its child with the filled size",
+			book:      []testOrder{ask(2, 100, 3)},
+			grouping:  groupOneTriggersOther,
+			group:     []testOrder{bid(1, 100, 4).with(orderLimit, tifImmediateOrCancel), ask(1, 110, 4)},
+			status:    []string{statusCanceled, statusOpen},
+			remaining: []int64{1, 3},
+		},
+		{
+			name:      "unfilled parent cancels its child",
+			grouping:  groupOneTriggersOther,
+			group:     []testOrder{bid(1, 100, 4).with(orderLimit, tifImmediateOrCancel), ask(1, 110, 4)},
+			status:    []string{statusCanceled, statusCanceled},
+			remaining: []int64{4, 4},
+		},
+		{
+			name:      "resting parent holds its child",
+			grouping:  groupOneTriggersOther,
+			group:     []testOrder{bid(1, 100, 4), ask(1, 110, 4)},
+			status:    []string{statusOpen, statusPending},
+			remaining: []int64{4, 4},
+		},
+		{
+			name:      "fill cancels the other order of the pair",
+			grouping:  groupOneCancelsOther,
+			group:     []testOrder{ask(1, 110, 2), ask(1, 95, 2)},
+			after:     []testOrder{bid(2, 110, 1)},
+			status:    []string{statusCanceledOCO, statusOpen},
+			remaining: []int64{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			s.placeTest(tt.book...)
+			group := s.groupTest(tt.grouping, tt.group...)
+			s.placeTest(tt.after...)
+
+			for i, o := range group {
+				if o.Status != tt.status[i] || o.remaining != tt.remaining[i] {
+					t.Errorf("order %d %s remaining %d, want %s remaining %d", i, o.Status, o.remaining, tt.status[i], tt.remaining[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCloseOrderLeavesBook(t *testing.T) {
+	s := newTestServer()
+	o := s.placeTest(ask(2, 100, 2))[0]
+
+	s.mu.Lock()
+	s.closeOrder(o, statusCanceled, newChanges(s.now(), ""))
+	s.mu.Unlock()
+
+	m := s.markets[0]
+	if len(m.asks) != 0 {
+		t.Fatalf("canceled order still rests: %d asks", len(m.asks))
+	}
+	acc := s.accounts[2]
+	if _, ok := acc.orders[o.OrderIndex]; ok || len(acc.inactive) != 1 || o.Status != statusCanceled {
+		t.Fatalf("order not moved to inactive: %s", o.Status)
+	}
+
+	taker := s.placeTest(bid(1, 100, 2))[0]
+	if taker.Status != statusOpen || taker.filled != 0 {
+		t.Fatalf("taker matched a canceled order: %s filled %d", taker.Status, taker.filled)
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger testOrder
+		// after is placed once the trigger order is; mark is then set
+		// unless zero
+		after    []testOrder
+		mark     int64
+		status   string
+		position int64
+	}{
+		{
+			name:    "sell stop waits while the mark is above it",
+			trigger: ask(1, 90, 1).triggerAt(orderStopLoss, tifImmediateOrCancel, 95),
+			after:   []testOrder{bid(3, 96, 1)},
+			mark:    96,
+			status:  statusPending,
+		},
+		{
+			name:     "sell stop fires at its trigger price",
+			trigger:  ask(1, 90, 1).triggerAt(orderStopLoss, tifImmediateOrCancel, 95),
+			after:    []testOrder{bid(3, 96, 1)},
+			mark:     95,
+			status:   statusFilled,
+			position: -1,
+		},
+		{
+			name:     "sell take profit fires above its trigger price",
+			trigger:  ask(1, 100, 1).triggerAt(orderTakeProfit, tifImmediateOrCancel, 105),
+			after:    []testOrder{bid(3, 101, 1)},
+			mark:     106,
+			status:   statusFilled,
+			position: -1,
+		},
+		{
+			name:    "stop loss limit rests when its price does not cross",
+			trigger: ask(1, 97, 1).triggerAt(orderStopLossLimit, tifGoodTillTime, 95),
+			after:   []testOrder{bid(3, 96, 1)},
+			mark:    95,
+			status:  statusOpen,
+		},
+		{
+			name:     "trade at the trigger price fires it",
+			trigger:  ask(1, 90, 1).triggerAt(orderStopLoss, tifImmediateOrCancel, 95),
+			after:    []testOrder{bid(3, 94, 1), ask(2, 95, 1), bid(3, 95, 1)},
+			status:   statusFilled,
+			position: -1,
+		},
+		{
+			name:    "reduce only stop waits for the position it protects",
+			trigger: ask(1, 90, 2).triggerAt(orderStopLoss, tifImmediateOrCancel, 95).reduce(),
+			after:   []testOrder{ask(2, 100, 2), bid(1, 100, 2), bid(3, 96, 2)},
+			mark:    95,
+			status:  statusFilled,
+		},
+		{
+			name:    "reduce only stop without a position is canceled when it fires",
+			trigger: ask(1, 90, 2).triggerAt(orderStopLoss, tifImmediateOrCancel, 95).reduce(),
+			after:   []testOrder{bid(3, 96, 2)},
+			mark:    95,
+			status:  statusCanceledReduceOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			o := s.placeTest(tt.trigger)[0]
+			if o.Status != statusPending {
+				t.Fatalf("placed trigger order %s, want %s", o.Status, statusPending)
+			}
+			s.placeTest(tt.after...)
+			if tt.mark != 0 {
+				if err := s.SetMarkPrice(0, tt.mark); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if o.Status != tt.status {
+				t.Errorf("trigger order %s, want %s", o.Status, tt.status)
+			}
+			if got := s.positionSize(1); got != tt.position {
+				t.Errorf("position %d, want %d", got, tt.position)
+			}
+		})
+	}
+}
+
+func TestTwap(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	// Four slices, 30s apart
+	twap := bid(1, 101, 4).with(orderTwap, tifGoodTillTime)
+	twap.expiry = start.Add(2 * time.Minute).UnixMilli()
+
+	type step struct {
+		after  time.Duration
+		filled int64
+		status string
+	}
+	tests := []struct {
+		name  string
+		book  []testOrder
+		steps []step
+	}{
+		{
+			name: "fills one slice per interval",
+			book: []testOrder{ask(2, 100, 10)},
+			steps: []step{
+				{0, 1, statusOpen},
+				{29 * time.Second, 1, statusOpen},
+				{30 * time.Second, 2, statusOpen},
+				{60 * time.Second, 3, statusOpen},
+				{90 * time.Second, 4, statusFilled},
+			},
+		},
+		{
+			name:  "catches up on missed slices",
+			book:  []testOrder{ask(2, 100, 10)},
+			steps: []step{{0, 1, statusOpen}, {90 * time.Second, 4, statusFilled}},
+		},
+		{
+			name: "cancels what the book cannot fill",
+			book: []testOrder{ask(2, 100, 2)},
+			steps: []step{
+				{0, 1, statusOpen},
+				{30 * time.Second, 2, statusOpen},
+				{90 * time.Second, 2, statusCanceledLiquidity},
+			},
+		},
+		{
+			name:  "does not trade through its price",
+			book:  []testOrder{ask(2, 102, 10)},
+			steps: []step{{0, 0, statusOpen}, {90 * time.Second, 0, statusCanceledLiquidity}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			now := start
+			s.Now = func() time.Time { return now }
+
+			s.placeTest(tt.book...)
+			o := s.placeTest(twap)[0]
+			for _, st := range tt.steps {
+				now = start.Add(st.after)
+				s.Advance()
+				if o.filled != st.filled || o.Status != st.status {
+					t.Errorf("after %v: %s filled %d, want %s filled %d", st.after, o.Status, o.filled, st.status, st.filled)
+				}
+			}
+		})
+	}
+}
+
+func TestPositionApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    position
+		isAsk    bool
+		qty      int64
+		price    int64
+		size     int64
+		open     int64
+		realized int64
+	}{
+		{"opens long", position{}, false, 10, 100, 10, 1000, 0},
+		{"opens short", position{}, true, 10, 100, -10, 1000, 0},
+		{"adds to long", position{10, 1000, 0}, false, 10, 200, 20, 3000, 0},
+		{"reduces long", position{10, 1000, 0}, true, 4, 150, 6, 600, 200},
+		{"closes long at a loss", position{10, 1000, 0}, true, 10, 90, 0, 0, -100},
+		{"reduces short", position{-10, 1000, 0}, false, 4, 80, -6, 600, 80},
+		{"flips long to short", position{10, 1000, 0}, true, 15, 120, -5, 600, 200},
+		{"flips short to long", position{-10, 1000, 50}, false, 12, 80, 2, 160, 250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.start
+			p.apply(tt.isAsk, tt.qty, tt.price)
+			want := position{tt.size, tt.open, tt.realized}
+			if p != want {
+				t.Errorf("apply = %+v, want %+v", p, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package mock
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"lighter-wasm/api"
+	"lighter-wasm/signing"
+)
+
+// Order statuses as the API reports them
+const (
+	statusPending            = "pending"
+	statusOpen               = "open"
+	statusFilled             = "filled"
+	statusCanceled           = "canceled"
+	statusCanceledPostOnly   = "canceled-post-only"
+	statusCanceledReduceOnly = "canceled-reduce-only"
+	statusCanceledLiquidity  = "canceled-not-enough-liquidity"
+	statusCanceledSelfTrade  = "canceled-self-trade"
+	statusCanceledExpired    = "canceled-expired"
+	statusCanceledOCO        = "canceled-oco"
+)
+
+// Order types, time in force and grouping types, as indexes into the
+// signing enums
+const (
+	orderLimit = iota
+	orderMarket
+	orderStopLoss
+	orderTakeProfit
+	orderStopLossLimit
+	orderTakeProfitLimit
+	orderTwap
+)
+
+const (
+	tifImmediateOrCancel = iota
+	tifGoodTillTime
+	tifPostOnly
+)
+
+const (
+	cancelAllImmediate = iota
+	cancelAllScheduled
+	cancelAllAbort
+)
+
+const (
+	groupNone = iota
+	groupOneTriggersOther
+	groupOneCancelsOther
+	groupOneTriggersOCO
+)
+
+// order is an api.Order with its amounts kept as integers
+type order struct {
+	api.Order
+	kind      uint8
+	tif       uint8
+	price     int64
+	trigger   int64
+	base      int64
+	remaining int64
+	filled    int64
+	// quote is the filled quote amount in price units times size units
+	quote int64
+	// children are placed once this order closes having filled; oco is
+	// canceled when this order fills
+	children []*order
+	oco      *order
+	// twapStart is when a TWAP began; twapDone counts the slices run
+	twapStart time.Time
+	twapDone  int
+}
+
+// enumName lowercases a constants.js name for the API, e.g. STOP_LOSS to
+// stop-loss
+func enumName(e *signing.Enum, value uint8) string {
+	name, ok := e.Lookup(int64(value))
+	if !ok {
+		return strconv.Itoa(int(value))
+	}
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// newOrder creates a pending order from an accepted tx; mu must be held.
+// The matching engine takes it from there.
+func (s *Server) newOrder(acc *account, info *txtypes.OrderInfo, tx Tx, now time.Time) *order {
+	m := s.markets[info.MarketIndex]
+	index := s.nextOrder
+	s.nextOrder++
+
+	side := "buy"
+	if info.IsAsk == 1 {
+		side = "sell"
+	}
+	o := &order{
+		kind:      info.Type,
+		tif:       info.TimeInForce,
+		price:     int64(info.Price),
+		trigger:   int64(info.TriggerPrice),
+		base:      info.BaseAmount,
+		remaining: info.BaseAmount,
+	}
+	o.Order = api.Order{
+		OrderIndex:        index,
+		ClientOrderIndex:  info.ClientOrderIndex,
+		OrderId:           strconv.FormatInt(index, 10),
+		ClientOrderId:     strconv.FormatInt(info.ClientOrderIndex, 10),
+		MarketIndex:       info.MarketIndex,
+		OwnerAccountIndex: acc.index,
+		Nonce:             tx.Nonce,
+		IsAsk:             info.IsAsk == 1,
+		BaseSize:          info.BaseAmount,
+		BasePrice:         int64(info.Price),
+		Side:              side,
+		Type:              enumName(&signing.OrderTypes, info.Type),
+		TimeInForce:       enumName(&signing.TimeInForces, info.TimeInForce),
+		ReduceOnly:        info.ReduceOnly == 1,
+		TriggerPrice:      m.format.FormatPrice(int64(info.TriggerPrice)),
+		OrderExpiry:       info.OrderExpiry,
+		Status:            statusPending,
+		TriggerStatus:     "na",
+		BlockHeight:       tx.Height,
+		Timestamp:         now.Unix(),
+	}
+	s.refresh(o)
+	acc.orders[index] = o
+	return o
+}
+
+// isTrigger reports whether o waits for the mark price before executing
+func (o *order) isTrigger() bool {
+	switch o.kind {
+	case orderStopLoss, orderTakeProfit, orderStopLossLimit, orderTakeProfitLimit:
+		return true
+	}
+	return false
+}
+
+// isMarket reports whether o executes immediately without resting, at
+// price or better
+func (o *order) isMarket() bool {
+	return o.kind == orderMarket || o.kind == orderStopLoss || o.kind == orderTakeProfit
+}
+
+// modifyOrder applies a ModifyOrder's fields to o; mu must be held
+func (s *Server) modifyOrder(o *order, tx *txtypes.L2ModifyOrderTxInfo, accepted Tx, now time.Time) {
+	o.price = int64(tx.Price)
+	o.trigger = int64(tx.TriggerPrice)
+	o.base = tx.BaseAmount
+	o.remaining = tx.BaseAmount - o.filled
+	if o.remaining < 0 {
+		o.remaining = 0
+	}
+	o.Nonce = accepted.Nonce
+	o.BaseSize = tx.BaseAmount
+	o.BasePrice = int64(tx.Price)
+	o.BlockHeight = accepted.Height
+	o.Timestamp = now.Unix()
+	o.TriggerPrice = s.markets[o.MarketIndex].format.FormatPrice(int64(tx.TriggerPrice))
+	s.refresh(o)
+}
+
+// refresh recomputes the decimal fields of o from its integers
+func (s *Server) refresh(o *order) {
+	f := s.markets[o.MarketIndex].format
+	o.Price = f.FormatPrice(o.price)
+	o.InitialBaseAmount = f.FormatSize(o.base)
+	o.RemainingBaseAmount = f.FormatSize(o.remaining)
+	o.FilledBaseAmount = f.FormatSize(o.filled)
+	// quote carries PriceDecimals+SizeDecimals fractional digits
+	o.FilledQuoteAmount = quoteString(o.quote, f.PriceDecimals+f.SizeDecimals)
+}
+
+func quoteString(n int64, decimals int) string {
+	if decimals == 0 {
+		return strconv.FormatInt(n, 10)
+	}
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	s := strconv.FormatInt(n, 10)
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	return sign + s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+}
+
+// findOrder looks an active order up by order index, falling back to
+// client order index
+func (a *account) findOrder(marketIndex uint8, index int64) *order {
+	if o, ok := a.orders[index]; ok && o.MarketIndex == marketIndex {
+		return o
+	}
+	for _, o := range a.orders {
+		if o.MarketIndex == marketIndex && o.ClientOrderIndex == index {
+			return o
+		}
+	}
+	return nil
+}
+
+// sortedOrders returns the active orders by ascending order index
+func (a *account) sortedOrders() []*order {
+	out := make([]*order, 0, len(a.orders))
+	for _, o := range a.orders {
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].OrderIndex < out[j].OrderIndex })
+	return out
+}
+
+// close moves an active order to the inactive list with status
+func (a *account) close(o *order, status string, now time.Time) {
+	if _, ok := a.orders[o.OrderIndex]; !ok {
+		return
+	}
+	delete(a.orders, o.OrderIndex)
+	o.Status = status
+	o.Timestamp = now.Unix()
+	a.inactive = append(a.inactive, o)
+}
@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"strconv"
+
+	"lighter-wasm/api"
+)
+
+// position is an account's exposure in one market. size is signed, long
+// positive; open is the entry cost of size and realized the closed PnL,
+// both in price units times size units.
+type position struct {
+	size     int64
+	open     int64
+	realized int64
+}
+
+// position returns the position in a market, creating a flat one
+func (a *account) position(marketId uint8) *position {
+	p, ok := a.positions[marketId]
+	if !ok {
+		p = &position{}
+		a.positions[marketId] = p
+	}
+	return p
+}
+
+// reducible is how much of o can fill without growing or flipping the
+// position, for reduce-only orders
+func (a *account) reducible(o *order) int64 {
+	p, ok := a.positions[o.MarketIndex]
+	switch {
+	case !ok:
+		return 0
+	case o.IsAsk && p.size > 0:
+		return p.size
+	case !o.IsAsk && p.size < 0:
+		return -p.size
+	}
+	return 0
+}
+
+// apply books a fill of qty at price on the side of isAsk
+func (p *position) apply(isAsk bool, qty, price int64) {
+	delta := qty
+	if isAsk {
+		delta = -qty
+	}
+	if p.size == 0 || (p.size > 0) == (delta > 0) {
+		p.size += delta
+		p.open += qty * price
+		return
+	}
+
+	// Close against the average entry first, then open the rest
+	held := abs(p.size)
+	closed := min(qty, held)
+	cost := p.open * closed / held
+	if p.size > 0 {
+		p.realized += closed*price - cost
+	} else {
+		p.realized += cost - closed*price
+	}
+	p.open -= cost
+	if p.size > 0 {
+		p.size -= closed
+	} else {
+		p.size += closed
+	}
+	if rest := qty - closed; rest > 0 {
+		p.size = rest
+		if isAsk {
+			p.size = -rest
+		}
+		p.open = rest * price
+	}
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// apiPosition reports p at the market's mark price
+func (s *Server) apiPosition(acc *account, m *market, p *position) api.Position {
+	f := m.format
+	decimals := f.PriceDecimals + f.SizeDecimals
+	held := abs(p.size)
+
+	out := api.Position{
+		MarketId:              m.detail.MarketId,
+		Symbol:                m.detail.Symbol,
+		Position:              f.FormatSize(held),
+		AvgEntryPrice:         f.FormatPrice(0),
+		PositionValue:         quoteString(held*m.mark, decimals),
+		UnrealizedPnl:         quoteString(0, decimals),
+		RealizedPnl:           quoteString(p.realized, decimals),
+		LiquidationPrice:      "0",
+		TotalFundingPaidOut:   "0",
+		AllocatedMargin:       "0",
+		InitialMarginFraction: "0",
+	}
+	for _, o := range acc.orders {
+		if o.MarketIndex != m.detail.MarketId {
+			continue
+		}
+		if o.Status == statusPending {
+			out.PendingOrderCount++
+		} else {
+			out.OpenOrderCount++
+		}
+	}
+	if held > 0 {
+		out.AvgEntryPrice = f.FormatPrice(p.open / held)
+		pnl := held*m.mark - p.open
+		out.Sign = 1
+		if p.size < 0 {
+			out.Sign = -1
+			pnl = -pnl
+		}
+		out.UnrealizedPnl = quoteString(pnl, decimals)
+	}
+	return out
+}
+
+// apiPositions reports every non-flat position of acc, or one market's
+// even if flat, keyed by market id
+func (s *Server) apiPositions(acc *account, only *market) map[string]api.Position {
+	out := make(map[string]api.Position)
+	for _, m := range s.sortedMarkets() {
+		p, ok := acc.positions[m.detail.MarketId]
+		if only != nil {
+			if m != only {
+				continue
+			}
+			if !ok {
+				p = &position{}
+			}
+		} else if !ok || (p.size == 0 && p.realized == 0) {
+			continue
+		}
+		out[strconv.Itoa(int(m.detail.MarketId))] = s.apiPosition(acc, m, p)
+	}
+	return out
+}
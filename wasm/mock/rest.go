@@ -0,0 +1,400 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lighter-wasm/api"
+)
+
+// writeJSON writes v with "code": 200 added, the envelope of every
+// successful API response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	fields["code"] = json.RawMessage("200")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorCode(w, status, status, message)
+}
+
+// writeErrorCode writes an error whose body code differs from the status
+func writeErrorCode(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": message})
+}
+
+// writeTxError rejects a submitted tx, with the exchange's code for a
+// wrong nonce
+func writeTxError(w http.ResponseWriter, err error) {
+	var nerr *nonceError
+	if errors.As(err, &nerr) {
+		writeErrorCode(w, http.StatusBadRequest, codeInvalidNonce, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
+
+// queryInt parses a required integer query parameter
+func queryInt(r *http.Request, name string, bits int) (int64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+	n, err := strconv.ParseInt(v, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", name, v)
+	}
+	return n, nil
+}
+
+func (s *Server) handleNextNonce(w http.ResponseWriter, r *http.Request) {
+	accountIndex, err := queryInt(r, "account_index", 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	apiKeyIndex, err := queryInt(r, "api_key_index", 9)
+	if err != nil || apiKeyIndex < 0 || apiKeyIndex > 255 {
+		writeError(w, http.StatusBadRequest, "invalid api_key_index")
+		return
+	}
+
+	s.mu.Lock()
+	_, ok := s.accounts[accountIndex]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "account not found")
+		return
+	}
+	writeJSON(w, api.NextNonceResponse{Nonce: s.NextNonce(accountIndex, uint8(apiKeyIndex))})
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	by := r.URL.Query().Get("by")
+	value := r.URL.Query().Get("value")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var acc *account
+	switch by {
+	case "index":
+		index, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid value")
+			return
+		}
+		acc = s.accounts[index]
+	case "l1_address":
+		acc = s.mainAccount(value)
+	default:
+		writeError(w, http.StatusBadRequest, "by must be index or l1_address")
+		return
+	}
+	if acc == nil {
+		writeError(w, http.StatusNotFound, "account not found")
+		return
+	}
+	writeJSON(w, api.AccountsResponse{Total: 1, Accounts: []api.DetailedAccount{s.detailedAccount(acc)}})
+}
+
+func (s *Server) handleAccountsByL1Address(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("l1_address")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := api.SubAccountsResponse{L1Address: address, SubAccounts: []api.Account{}}
+	for _, acc := range s.sortedAccounts() {
+		if address != "" && strings.EqualFold(acc.l1Address, address) {
+			resp.SubAccounts = append(resp.SubAccounts, s.accountSummary(acc))
+		}
+	}
+	if len(resp.SubAccounts) == 0 {
+		writeError(w, http.StatusNotFound, "account not found")
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// mainAccount returns the lowest indexed account of an L1 address; mu
+// must be held
+func (s *Server) mainAccount(address string) *account {
+	for _, acc := range s.sortedAccounts() {
+		if address != "" && strings.EqualFold(acc.l1Address, address) {
+			return acc
+		}
+	}
+	return nil
+}
+
+func (s *Server) sortedAccounts() []*account {
+	accs := make([]*account, 0, len(s.accounts))
+	for _, acc := range s.accounts {
+		accs = append(accs, acc)
+	}
+	sort.Slice(accs, func(i, j int) bool { return accs[i].index < accs[j].index })
+	return accs
+}
+
+func (s *Server) accountSummary(acc *account) api.Account {
+	return api.Account{
+		Index:            acc.index,
+		L1Address:        acc.l1Address,
+		TotalOrderCount:  int64(len(acc.orders)),
+		AvailableBalance: acc.collateral,
+		Status:           1,
+		Collateral:       acc.collateral,
+	}
+}
+
+func (s *Server) detailedAccount(acc *account) api.DetailedAccount {
+	byMarket := s.apiPositions(acc, nil)
+	positions := []api.Position{}
+	for _, m := range s.sortedMarkets() {
+		if p, ok := byMarket[strconv.Itoa(int(m.detail.MarketId))]; ok {
+			positions = append(positions, p)
+		}
+	}
+	return api.DetailedAccount{
+		Account:            s.accountSummary(acc),
+		AccountIndex:       acc.index,
+		TotalAssetValue:    acc.collateral,
+		CrossAssetValue:    acc.collateral,
+		TotalRealizedPnl:   "0",
+		TotalUnrealizedPnl: "0",
+		Positions:          positions,
+	}
+}
+
+func (s *Server) handleOrders(active bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountIndex, err := queryInt(r, "account_index", 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		marketId := api.AllMarkets
+		if r.URL.Query().Get("market_id") != "" {
+			n, err := queryInt(r, "market_id", 9)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			marketId = int(n)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		acc, ok := s.accounts[accountIndex]
+		if !ok {
+			writeError(w, http.StatusNotFound, "account not found")
+			return
+		}
+
+		orders := []api.Order{}
+		if active {
+			orders = acc.activeOrders(marketId)
+		} else {
+			for _, o := range acc.inactive {
+				if marketId < 0 || int(o.MarketIndex) == marketId {
+					orders = append(orders, o.Order)
+				}
+			}
+		}
+		writeJSON(w, api.OrdersResponse{Orders: orders})
+	}
+}
+
+func (s *Server) handleOrderBooks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := api.OrderBooksResponse{OrderBooks: []api.OrderBook{}}
+	for _, m := range s.sortedMarkets() {
+		resp.OrderBooks = append(resp.OrderBooks, m.detail.OrderBook)
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleOrderBookDetails(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := api.OrderBookDetailsResponse{OrderBookDetails: []api.OrderBookDetail{}}
+	filter := r.URL.Query().Get("market_id")
+	for _, m := range s.sortedMarkets() {
+		if filter == "" || filter == strconv.Itoa(int(m.detail.MarketId)) {
+			resp.OrderBookDetails = append(resp.OrderBookDetails, m.detail)
+		}
+	}
+	writeJSON(w, resp)
+}
+
+// marketParams parses market_id and an optional limit, writing the error
+// if there is one; mu must be held
+func (s *Server) marketParams(w http.ResponseWriter, r *http.Request) (*market, int, bool) {
+	id, err := queryInt(r, "market_id", 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, 0, false
+	}
+	m := s.market(id)
+	if m == nil {
+		writeError(w, http.StatusNotFound, "market not found")
+		return nil, 0, false
+	}
+	limit := int64(100)
+	if r.URL.Query().Get("limit") != "" {
+		if limit, err = queryInt(r, "limit", 32); err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return nil, 0, false
+		}
+	}
+	return m, int(limit), true
+}
+
+func (s *Server) handleOrderBookOrders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, limit, ok := s.marketParams(w, r)
+	if !ok {
+		return
+	}
+
+	resp := api.OrderBookOrdersResponse{
+		TotalAsks: int64(len(m.asks)),
+		Asks:      bookOrders(m.asks, limit),
+		TotalBids: int64(len(m.bids)),
+		Bids:      bookOrders(m.bids, limit),
+	}
+	writeJSON(w, resp)
+}
+
+func bookOrders(side []*order, limit int) []api.BookOrder {
+	out := []api.BookOrder{}
+	for _, o := range side {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, api.BookOrder{
+			OrderIndex:          o.OrderIndex,
+			OrderId:             o.OrderId,
+			OwnerAccountIndex:   o.OwnerAccountIndex,
+			InitialBaseAmount:   o.InitialBaseAmount,
+			RemainingBaseAmount: o.RemainingBaseAmount,
+			Price:               o.Price,
+			OrderExpiry:         o.OrderExpiry,
+		})
+	}
+	return out
+}
+
+// handleRecentTrades lists the latest trades of a market, newest first
+func (s *Server) handleRecentTrades(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, limit, ok := s.marketParams(w, r)
+	if !ok {
+		return
+	}
+
+	trades := []api.Trade{}
+	for i := len(m.trades) - 1; i >= 0 && len(trades) < limit; i-- {
+		trades = append(trades, m.trades[i])
+	}
+	writeJSON(w, api.TradesResponse{Trades: trades})
+}
+
+func (s *Server) sortedMarkets() []*market {
+	out := make([]*market, 0, len(s.markets))
+	for id := 0; id < 256; id++ {
+		if m, ok := s.markets[uint8(id)]; ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *Server) handleSendTx(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	txType, err := strconv.ParseUint(r.FormValue("tx_type"), 10, 8)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tx_type")
+		return
+	}
+
+	hashes, err := s.submit([]uint8{uint8(txType)}, []string{r.FormValue("tx_info")})
+	if err != nil {
+		writeTxError(w, err)
+		return
+	}
+	writeJSON(w, api.SendTxResponse{TxHash: hashes[0]})
+}
+
+func (s *Server) handleSendTxBatch(w http.ResponseWriter, r *http.Request) {
+	if !parseForm(w, r) {
+		return
+	}
+	var txTypes []uint8
+	var txInfos []string
+	if err := json.Unmarshal([]byte(r.FormValue("tx_types")), &txTypes); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tx_types")
+		return
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("tx_infos")), &txInfos); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tx_infos")
+		return
+	}
+	if len(txTypes) == 0 || len(txTypes) != len(txInfos) {
+		writeError(w, http.StatusBadRequest, "tx_types and tx_infos must be non-empty and of equal length")
+		return
+	}
+
+	hashes, err := s.submit(txTypes, txInfos)
+	if err != nil {
+		writeTxError(w, err)
+		return
+	}
+	writeJSON(w, api.SendTxBatchResponse{TxHash: hashes})
+}
+
+// parseForm accepts multipart and urlencoded POST bodies
+func parseForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST required")
+		return false
+	}
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		err = r.ParseMultipartForm(1 << 20)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid form: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,313 @@
+// Package mock is an in-memory stand-in for the Lighter API, for testing
+// bots hermetically. It serves the REST endpoints a trading client needs
+// and the /stream WebSocket, verifies tx signatures against registered API
+// keys, enforces nonces and expiry, and matches orders with price-time
+// priority so fills, trades and positions are reported like the real
+// exchange does. There is no margin check, fee or funding.
+//
+//	srv := mock.NewServer(api.TestnetChainId)
+//	srv.AddMarket(api.OrderBookDetail{...})
+//	srv.AddAccount(5, "0x...")
+//	srv.RegisterKey(5, 3, publicKey)
+//	ts := httptest.NewServer(srv)
+//	client := api.NewClient(ts.URL)
+//
+// The stream is at ws://<host>/stream. Account channels need an unexpired
+// auth token signed by a registered API key of the account.
+//
+// Trigger orders fire on the mark price, which is the last trade price
+// unless set with SetMarkPrice. TWAP slices, order expiry and scheduled
+// cancel-alls follow Now; call Advance after moving the clock to run them
+// without sending a tx.
+package mock
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"lighter-wasm/api"
+	"lighter-wasm/orderbook"
+)
+
+// Tx is an accepted tx
+type Tx struct {
+	Hash         string
+	Type         uint8
+	Info         string
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64
+	Height       int64
+	Time         time.Time
+}
+
+// Server is the mock exchange. It is safe for concurrent use; configure it
+// before or while serving.
+type Server struct {
+	ChainId uint32
+	// Now is the clock for expiry checks and timestamps; nil means
+	// time.Now
+	Now func() time.Time
+
+	mu        sync.Mutex
+	markets   map[uint8]*market
+	accounts  map[int64]*account
+	txs       []Tx
+	nextOrder int64
+	nextTrade int64
+	conns     map[*conn]struct{}
+	mux       *http.ServeMux
+}
+
+type market struct {
+	detail api.OrderBookDetail
+	format orderbook.Market
+
+	bids, asks []*order
+	// triggers wait for the mark price; twaps run in slices
+	triggers []*order
+	twaps    []*order
+	mark     int64
+	trades   []api.Trade
+	// nonce and offset sequence the order_book messages
+	nonce  int64
+	offset int64
+}
+
+type account struct {
+	index      int64
+	l1Address  string
+	collateral string
+	keys       map[uint8]*apiKey
+	// orders holds the active orders by order index, inactive the rest
+	// in the order they closed
+	orders    map[int64]*order
+	inactive  []*order
+	positions map[uint8]*position
+	trades    []api.Trade
+	// cancelAt is when a scheduled cancel-all closes every order, in
+	// milliseconds; zero if none is scheduled
+	cancelAt int64
+}
+
+type apiKey struct {
+	publicKey []byte
+	nonce     int64
+}
+
+// NewServer returns an empty exchange for chainId
+func NewServer(chainId uint32) *Server {
+	s := &Server{
+		ChainId:   chainId,
+		markets:   make(map[uint8]*market),
+		accounts:  make(map[int64]*account),
+		nextOrder: 1,
+		nextTrade: 1,
+		conns:     make(map[*conn]struct{}),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// AddMarket lists a market. SizeDecimals and PriceDecimals define how the
+// integer amounts of order txs are reported.
+func (s *Server) AddMarket(detail api.OrderBookDetail) {
+	if detail.Status == "" {
+		detail.Status = "active"
+	}
+	if detail.SupportedSizeDecimals == 0 {
+		detail.SupportedSizeDecimals = detail.SizeDecimals
+	}
+	if detail.SupportedPriceDecimals == 0 {
+		detail.SupportedPriceDecimals = detail.PriceDecimals
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markets[detail.MarketId] = &market{
+		detail: detail,
+		format: orderbook.Market{
+			Id:            detail.MarketId,
+			Symbol:        detail.Symbol,
+			PriceDecimals: detail.PriceDecimals,
+			SizeDecimals:  detail.SizeDecimals,
+		},
+	}
+}
+
+// AddAccount creates an account. ChangePubKey txs need the L1 signature of
+// l1Address; with an empty l1Address keys can only be set with RegisterKey.
+func (s *Server) AddAccount(index int64, l1Address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accounts[index]; ok {
+		return
+	}
+	s.accounts[index] = &account{
+		index:      index,
+		l1Address:  l1Address,
+		collateral: "0",
+		keys:       make(map[uint8]*apiKey),
+		orders:     make(map[int64]*order),
+		positions:  make(map[uint8]*position),
+	}
+}
+
+// SetCollateral sets the collateral an account reports, a decimal string
+func (s *Server) SetCollateral(accountIndex int64, collateral string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accounts[accountIndex]
+	if !ok {
+		return fmt.Errorf("account %d not found", accountIndex)
+	}
+	acc.collateral = collateral
+	return nil
+}
+
+// RegisterKey sets the public key of an API key, keeping its nonce, as an
+// accepted ChangePubKey tx would
+func (s *Server) RegisterKey(accountIndex int64, apiKeyIndex uint8, publicKey []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accounts[accountIndex]
+	if !ok {
+		return fmt.Errorf("account %d not found", accountIndex)
+	}
+	acc.key(apiKeyIndex).publicKey = append([]byte(nil), publicKey...)
+	return nil
+}
+
+// key returns an API key, creating an unregistered one at nonce 0
+func (a *account) key(apiKeyIndex uint8) *apiKey {
+	k, ok := a.keys[apiKeyIndex]
+	if !ok {
+		k = &apiKey{}
+		a.keys[apiKeyIndex] = k
+	}
+	return k
+}
+
+// NextNonce returns the nonce the next tx of an API key must use
+func (s *Server) NextNonce(accountIndex int64, apiKeyIndex uint8) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if acc, ok := s.accounts[accountIndex]; ok {
+		if k, ok := acc.keys[apiKeyIndex]; ok {
+			return k.nonce
+		}
+	}
+	return 0
+}
+
+// Txs returns the accepted txs in order
+func (s *Server) Txs() []Tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Tx(nil), s.txs...)
+}
+
+// Orders returns the active orders of an account by ascending order index
+func (s *Server) Orders(accountIndex int64) []api.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accounts[accountIndex]
+	if !ok {
+		return nil
+	}
+	return acc.activeOrders(-1)
+}
+
+// SetMarkPrice moves the price trigger orders of a market are checked
+// against, in integer price units, and fires those it reaches
+func (s *Server) SetMarkPrice(marketId uint8, price int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.markets[marketId]
+	if !ok {
+		return fmt.Errorf("market %d not found", marketId)
+	}
+	m.mark = price
+	ch := newChanges(s.now(), "")
+	s.settle(ch)
+	s.publishChanges(ch)
+	return nil
+}
+
+// Advance runs the TWAP slices, order expiries and scheduled cancel-alls
+// due at Now. Each tx does this before it executes.
+func (s *Server) Advance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := newChanges(s.now(), "")
+	s.advance(ch)
+	s.publishChanges(ch)
+}
+
+// Position returns the signed position of an account in integer size
+// units, long positive
+func (s *Server) Position(accountIndex int64, marketId uint8) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if acc, ok := s.accounts[accountIndex]; ok {
+		if p, ok := acc.positions[marketId]; ok {
+			return p.size
+		}
+	}
+	return 0
+}
+
+// activeOrders lists the active orders, of one market unless marketId is
+// negative
+func (a *account) activeOrders(marketId int) []api.Order {
+	out := []api.Order{}
+	for _, o := range a.orders {
+		if marketId < 0 || int(o.MarketIndex) == marketId {
+			out = append(out, o.Order)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].OrderIndex < out[j].OrderIndex })
+	return out
+}
+
+// ServeHTTP serves /api/v1/* and /stream
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/stream", s.serveStream)
+
+	handlers := map[string]http.HandlerFunc{
+		"sendTx":                s.handleSendTx,
+		"sendTxBatch":           s.handleSendTxBatch,
+		"nextNonce":             s.handleNextNonce,
+		"account":               s.handleAccount,
+		"accountsByL1Address":   s.handleAccountsByL1Address,
+		"accountActiveOrders":   s.handleOrders(true),
+		"accountInactiveOrders": s.handleOrders(false),
+		"orderBooks":            s.handleOrderBooks,
+		"orderBookDetails":      s.handleOrderBookDetails,
+		"orderBookOrders":       s.handleOrderBookOrders,
+		"recentTrades":          s.handleRecentTrades,
+		"trades":                s.handleRecentTrades,
+	}
+	for name, h := range handlers {
+		s.mux.HandleFunc("/api/v1/"+name, h)
+	}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not found: "+strings.TrimPrefix(r.URL.Path, "/api/v1/"))
+	})
+}
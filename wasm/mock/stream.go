@@ -0,0 +1,458 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"lighter-wasm/api"
+	"lighter-wasm/signing"
+	"lighter-wasm/stream"
+)
+
+// sendQueueSize bounds the messages buffered per stream connection; a
+// client that falls this far behind is disconnected
+const sendQueueSize = 256
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// conn is one stream client. Its fields besides ws are guarded by
+// Server.mu.
+type conn struct {
+	ws    *websocket.Conn
+	send  chan interface{}
+	subs  map[string]bool
+	token string
+	alive bool
+}
+
+// queue sends msg without blocking; mu must be held
+func (c *conn) queue(msg interface{}) {
+	if !c.alive {
+		return
+	}
+	select {
+	case c.send <- msg:
+	default:
+		c.alive = false
+		close(c.send)
+	}
+}
+
+func (c *conn) writeLoop() {
+	defer c.ws.Close()
+	for msg := range c.send {
+		c.ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := c.ws.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{
+		ws:    ws,
+		send:  make(chan interface{}, sendQueueSize),
+		subs:  make(map[string]bool),
+		alive: true,
+	}
+	go c.writeLoop()
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	c.queue(map[string]interface{}{"type": "connected", "session_id": fmt.Sprintf("%p", c)})
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		if c.alive {
+			c.alive = false
+			close(c.send)
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg struct {
+			Type    string `json:"type"`
+			Channel string `json:"channel"`
+			Auth    string `json:"auth"`
+			Token   string `json:"token"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.mu.Lock()
+			c.queue(streamError("", "invalid message"))
+			s.mu.Unlock()
+			continue
+		}
+
+		s.mu.Lock()
+		switch msg.Type {
+		case "ping":
+			c.queue(map[string]interface{}{"type": "pong"})
+		case "auth":
+			c.token = msg.Token
+			c.queue(map[string]interface{}{"type": "auth"})
+		case "subscribe":
+			auth := msg.Auth
+			if auth == "" {
+				auth = c.token
+			}
+			s.subscribe(c, msg.Channel, auth)
+		case "unsubscribe":
+			delete(c.subs, channelKey(msg.Channel))
+		}
+		s.mu.Unlock()
+	}
+}
+
+func streamError(channel, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "error",
+		"channel": channel,
+		"error":   map[string]interface{}{"code": 400, "message": message},
+	}
+}
+
+// channelKey converts "account_orders/0/5" to the server's
+// "account_orders:0:5"
+func channelKey(channel string) string {
+	return strings.ReplaceAll(channel, "/", ":")
+}
+
+// channelSpec describes a supported channel: the number of ids after its
+// name, which of them is the account whose auth token it needs, or -1 if
+// it is public, and its snapshot, which is nil if the market or account
+// does not exist
+type channelSpec struct {
+	ids      int
+	account  int
+	snapshot func(s *Server, ids []int64) map[string]interface{}
+}
+
+var channels = map[string]channelSpec{
+	"order_book": {1, -1, func(s *Server, ids []int64) map[string]interface{} {
+		if m := s.market(ids[0]); m != nil {
+			return s.bookMessage(m, m.nonce, allLevels(m))
+		}
+		return nil
+	}},
+	"trade": {1, -1, func(s *Server, ids []int64) map[string]interface{} {
+		if m := s.market(ids[0]); m != nil {
+			return map[string]interface{}{"trades": append([]api.Trade{}, m.trades...)}
+		}
+		return nil
+	}},
+	"account_orders": {2, 1, func(s *Server, ids []int64) map[string]interface{} {
+		if s.market(ids[0]) == nil {
+			return nil
+		}
+		return s.ordersMessage(ids[1], int(ids[0]))
+	}},
+	"account_all_orders": {1, 0, func(s *Server, ids []int64) map[string]interface{} {
+		return s.ordersMessage(ids[0], api.AllMarkets)
+	}},
+	"account_all_trades": {1, 0, func(s *Server, ids []int64) map[string]interface{} {
+		if acc, ok := s.accounts[ids[0]]; ok {
+			return map[string]interface{}{"trades": tradesByMarket(acc.trades)}
+		}
+		return nil
+	}},
+	"account_all_positions": {1, 0, func(s *Server, ids []int64) map[string]interface{} {
+		if acc, ok := s.accounts[ids[0]]; ok {
+			return map[string]interface{}{"positions": s.apiPositions(acc, nil), "shares": []interface{}{}}
+		}
+		return nil
+	}},
+	"account_all": {1, -1, func(s *Server, ids []int64) map[string]interface{} {
+		if acc, ok := s.accounts[ids[0]]; ok {
+			return map[string]interface{}{
+				"account":   acc.index,
+				"positions": s.apiPositions(acc, nil),
+				"trades":    tradesByMarket(acc.trades),
+				"shares":    []interface{}{},
+			}
+		}
+		return nil
+	}},
+}
+
+// market returns the market of a channel id, or nil
+func (s *Server) market(id int64) *market {
+	if id < 0 || id > 255 {
+		return nil
+	}
+	return s.markets[uint8(id)]
+}
+
+// subscribe registers c on channel and sends its snapshot; mu must be held
+func (s *Server) subscribe(c *conn, channel, auth string) {
+	key := channelKey(channel)
+	parts := strings.Split(key, ":")
+	spec, ok := channels[parts[0]]
+	if !ok || len(parts)-1 != spec.ids {
+		c.queue(streamError(key, "unsupported channel"))
+		return
+	}
+	ids := make([]int64, spec.ids)
+	for i, p := range parts[1:] {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			c.queue(streamError(key, "invalid channel"))
+			return
+		}
+		ids[i] = n
+	}
+	if spec.account >= 0 {
+		if err := s.checkAuth(auth, ids[spec.account]); err != nil {
+			c.queue(streamError(key, err.Error()))
+			return
+		}
+	}
+	snapshot := spec.snapshot(s, ids)
+	if snapshot == nil {
+		c.queue(streamError(key, "market or account not found"))
+		return
+	}
+
+	c.subs[key] = true
+	snapshot["type"] = "subscribed/" + parts[0]
+	snapshot["channel"] = key
+	c.queue(snapshot)
+}
+
+// checkAuth verifies that token is a live auth token signed by a
+// registered API key of accountIndex; mu must be held
+func (s *Server) checkAuth(token string, accountIndex int64) error {
+	if token == "" {
+		return fmt.Errorf("auth required")
+	}
+	claims, err := signing.ParseAuthToken(token)
+	if err != nil {
+		return err
+	}
+	if claims.AccountIndex != accountIndex {
+		return fmt.Errorf("auth token is for account %d", claims.AccountIndex)
+	}
+	acc, ok := s.accounts[accountIndex]
+	if !ok {
+		return fmt.Errorf("market or account not found")
+	}
+	k, ok := acc.keys[claims.ApiKeyIndex]
+	if !ok || len(k.publicKey) == 0 {
+		return fmt.Errorf("api key %d of account %d is not registered", claims.ApiKeyIndex, accountIndex)
+	}
+	return claims.Verify(k.publicKey, s.now())
+}
+
+// ordersMessage is the body of an account_orders message holding every
+// active order of an account in one market, or all markets
+func (s *Server) ordersMessage(accountIndex int64, marketId int) map[string]interface{} {
+	acc, ok := s.accounts[accountIndex]
+	if !ok {
+		return nil
+	}
+	orders := make(map[string][]api.Order)
+	for _, o := range acc.activeOrders(marketId) {
+		id := strconv.Itoa(int(o.MarketIndex))
+		orders[id] = append(orders[id], o)
+	}
+	if marketId >= 0 {
+		id := strconv.Itoa(marketId)
+		if orders[id] == nil {
+			orders[id] = []api.Order{}
+		}
+	}
+	return map[string]interface{}{
+		"account": accountIndex,
+		"nonce":   int64(len(s.txs)),
+		"orders":  orders,
+	}
+}
+
+// bookMessage is the body of an order_book message holding levels, from
+// beginNonce to the market's current nonce
+func (s *Server) bookMessage(m *market, beginNonce int64, levels []level) map[string]interface{} {
+	asks := []stream.PriceLevel{}
+	bids := []stream.PriceLevel{}
+	for _, l := range levels {
+		pl := stream.PriceLevel{
+			Price: m.format.FormatPrice(l.price),
+			Size:  m.format.FormatSize(m.levelSize(l)),
+		}
+		if l.isAsk {
+			asks = append(asks, pl)
+		} else {
+			bids = append(bids, pl)
+		}
+	}
+	return map[string]interface{}{
+		"offset":    m.offset,
+		"timestamp": s.now().UnixMilli(),
+		"order_book": stream.OrderBookState{
+			Asks:       asks,
+			Bids:       bids,
+			Offset:     m.offset,
+			Nonce:      m.nonce,
+			BeginNonce: beginNonce,
+		},
+	}
+}
+
+// allLevels lists every price of a book, best first on each side
+func allLevels(m *market) []level {
+	var out []level
+	for _, isAsk := range []bool{true, false} {
+		for _, o := range *m.side(isAsk) {
+			if n := len(out); n == 0 || out[n-1] != (level{isAsk, o.price}) {
+				out = append(out, level{isAsk, o.price})
+			}
+		}
+	}
+	return out
+}
+
+func tradesByMarket(trades []api.Trade) map[string][]api.Trade {
+	out := make(map[string][]api.Trade)
+	for _, t := range trades {
+		id := strconv.Itoa(int(t.MarketId))
+		out[id] = append(out[id], t)
+	}
+	return out
+}
+
+// publishChanges sends the stream messages for ch; mu must be held
+func (s *Server) publishChanges(ch *changes) {
+	for _, m := range ch.markets {
+		levels := make([]level, 0, len(ch.levels[m]))
+		for l := range ch.levels[m] {
+			levels = append(levels, l)
+		}
+		sort.Slice(levels, func(i, j int) bool {
+			if levels[i].isAsk != levels[j].isAsk {
+				return levels[i].isAsk
+			}
+			return levels[i].price < levels[j].price
+		})
+		if len(levels) > 0 {
+			begin := m.nonce
+			m.nonce++
+			m.offset++
+			key := fmt.Sprintf("order_book:%d", m.detail.MarketId)
+			msg := s.bookMessage(m, begin, levels)
+			msg["type"] = "update/order_book"
+			msg["channel"] = key
+			s.publish(key, msg)
+		}
+
+		if trades := ch.tradesByMkt[m]; len(trades) > 0 {
+			key := fmt.Sprintf("trade:%d", m.detail.MarketId)
+			s.publish(key, map[string]interface{}{
+				"type":    "update/trade",
+				"channel": key,
+				"trades":  trades,
+			})
+		}
+	}
+
+	for _, acc := range ch.accounts {
+		s.notifyOrders(acc, ch.orders[acc])
+
+		positions := make(map[string]api.Position)
+		for m := range ch.positions[acc] {
+			for id, p := range s.apiPositions(acc, m) {
+				positions[id] = p
+			}
+		}
+		trades := tradesByMarket(ch.trades[acc])
+		if len(positions) > 0 {
+			key := fmt.Sprintf("account_all_positions:%d", acc.index)
+			s.publish(key, map[string]interface{}{
+				"type":      "update/account_all_positions",
+				"channel":   key,
+				"positions": positions,
+				"shares":    []interface{}{},
+			})
+		}
+		if len(trades) > 0 {
+			key := fmt.Sprintf("account_all_trades:%d", acc.index)
+			s.publish(key, map[string]interface{}{
+				"type":    "update/account_all_trades",
+				"channel": key,
+				"trades":  trades,
+			})
+		}
+		if len(positions) > 0 || len(trades) > 0 {
+			key := fmt.Sprintf("account_all:%d", acc.index)
+			s.publish(key, map[string]interface{}{
+				"type":      "update/account_all",
+				"channel":   key,
+				"account":   acc.index,
+				"positions": positions,
+				"trades":    trades,
+			})
+		}
+	}
+}
+
+// notifyOrders sends the changed orders of acc, including closed ones, to
+// the account_orders and account_all_orders subscribers; mu must be held
+func (s *Server) notifyOrders(acc *account, changed []*order) {
+	if len(changed) == 0 {
+		return
+	}
+
+	byMarket := make(map[uint8][]api.Order)
+	for _, o := range changed {
+		byMarket[o.MarketIndex] = append(byMarket[o.MarketIndex], o.Order)
+	}
+
+	for marketId, orders := range byMarket {
+		key := fmt.Sprintf("account_orders:%d:%d", marketId, acc.index)
+		s.publish(key, map[string]interface{}{
+			"type":    "update/account_orders",
+			"channel": key,
+			"account": acc.index,
+			"nonce":   int64(len(s.txs)),
+			"orders":  map[string][]api.Order{strconv.Itoa(int(marketId)): orders},
+		})
+	}
+
+	all := make(map[string][]api.Order, len(byMarket))
+	for marketId, orders := range byMarket {
+		all[strconv.Itoa(int(marketId))] = orders
+	}
+	key := fmt.Sprintf("account_all_orders:%d", acc.index)
+	s.publish(key, map[string]interface{}{
+		"type":    "update/account_all_orders",
+		"channel": key,
+		"account": acc.index,
+		"nonce":   int64(len(s.txs)),
+		"orders":  all,
+	})
+}
+
+// publish sends msg to every subscriber of key; mu must be held
+func (s *Server) publish(key string, msg interface{}) {
+	for c := range s.conns {
+		if c.subs[key] {
+			c.queue(msg)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package mock
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"lighter-wasm/signing"
+)
+
+// txHeader holds the fields every L2 tx info carries
+type txHeader struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64
+	ExpiredAt    int64
+}
+
+type keyRef struct {
+	account int64
+	apiKey  uint8
+}
+
+// codeInvalidNonce is the code of the exchange's response to a tx with the
+// wrong nonce, API_ERROR_CODES.INVALID_NONCE in sdk/core/constants.js
+const codeInvalidNonce = 21104
+
+// nonceError rejects a tx whose nonce is not the next one of its API key
+type nonceError struct {
+	expected, got int64
+}
+
+func (e *nonceError) Error() string {
+	return fmt.Sprintf("invalid nonce: expected %d, got %d", e.expected, e.got)
+}
+
+// pending is a verified tx waiting to be executed
+type pending struct {
+	tx      Tx
+	acc     *account
+	decoded txtypes.TxInfo
+	pubKey  []byte
+}
+
+// submit verifies every tx, then executes them in order. A batch is
+// accepted or rejected as a whole; execution itself never fails, so a
+// cancel of an unknown order is accepted and does nothing, as on the real
+// exchange.
+func (s *Server) submit(txTypes []uint8, txInfos []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	due := newChanges(now, "")
+	s.advance(due)
+	s.publishChanges(due)
+
+	nonces := make(map[keyRef]int64)
+	keys := make(map[keyRef][]byte)
+	batch := make([]*pending, len(txTypes))
+	for i := range txTypes {
+		p, err := s.verify(txTypes[i], txInfos[i], now, nonces, keys)
+		if err != nil {
+			if len(txTypes) > 1 {
+				return nil, fmt.Errorf("tx %d: %w", i, err)
+			}
+			return nil, err
+		}
+		batch[i] = p
+	}
+
+	height := int64(len(s.txs)) + 1
+	hashes := make([]string, len(batch))
+	for i, p := range batch {
+		p.tx.Height = height
+		s.txs = append(s.txs, p.tx)
+		p.acc.key(p.tx.ApiKeyIndex).nonce = p.tx.Nonce + 1
+		s.execute(p, now)
+		hashes[i] = p.tx.Hash
+	}
+	return hashes, nil
+}
+
+// verify checks a tx's account, expiry, nonce and signature. nonces holds
+// the next nonce of keys already used earlier in the batch, keys the public
+// keys installed by its earlier ChangePubKey txs.
+func (s *Server) verify(txType uint8, txInfo string, now time.Time, nonces map[keyRef]int64, keys map[keyRef][]byte) (*pending, error) {
+	decoded, sig, err := signing.ParseTx(txType, txInfo)
+	if err != nil {
+		return nil, err
+	}
+	var h txHeader
+	if err := json.Unmarshal([]byte(txInfo), &h); err != nil {
+		return nil, fmt.Errorf("invalid tx info: %v", err)
+	}
+
+	acc, ok := s.accounts[h.AccountIndex]
+	if !ok {
+		return nil, fmt.Errorf("account %d not found", h.AccountIndex)
+	}
+
+	nowMs := now.UnixMilli()
+	if h.ExpiredAt <= nowMs {
+		return nil, fmt.Errorf("tx expired at %d, now %d", h.ExpiredAt, nowMs)
+	}
+	if h.ExpiredAt > now.Add(signing.MaxExpiryWindow).UnixMilli() {
+		return nil, fmt.Errorf("expiredAt %d is more than %v ahead", h.ExpiredAt, signing.MaxExpiryWindow)
+	}
+
+	ref := keyRef{h.AccountIndex, h.ApiKeyIndex}
+	expected, ok := nonces[ref]
+	if !ok {
+		if k, ok := acc.keys[h.ApiKeyIndex]; ok {
+			expected = k.nonce
+		}
+	}
+	if h.Nonce != expected {
+		return nil, &nonceError{expected, h.Nonce}
+	}
+
+	// A ChangePubKey is signed by the key it installs and authorized by
+	// the account owner's L1 signature
+	var pubKey []byte
+	if t, ok := decoded.(*txtypes.L2ChangePubKeyTxInfo); ok {
+		pubKey = t.PubKey
+		if err := checkL1(acc, t); err != nil {
+			return nil, err
+		}
+	} else if key, ok := keys[ref]; ok {
+		pubKey = key
+	} else if k, ok := acc.keys[h.ApiKeyIndex]; ok && len(k.publicKey) > 0 {
+		pubKey = k.publicKey
+	} else {
+		return nil, fmt.Errorf("api key %d of account %d is not registered", h.ApiKeyIndex, h.AccountIndex)
+	}
+
+	msgHash, err := signing.VerifyTx(decoded, sig, s.ChainId, pubKey)
+	if msgHash == nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %v", err)
+	}
+
+	if t, ok := decoded.(*txtypes.L2CreateOrderTxInfo); ok && t.OrderInfo != nil {
+		if _, ok := s.markets[t.MarketIndex]; !ok {
+			return nil, fmt.Errorf("market %d not found", t.MarketIndex)
+		}
+	}
+	if t, ok := decoded.(*txtypes.L2CreateGroupedOrdersTxInfo); ok {
+		for _, info := range t.Orders {
+			if _, ok := s.markets[info.MarketIndex]; !ok {
+				return nil, fmt.Errorf("market %d not found", info.MarketIndex)
+			}
+		}
+	}
+
+	if t, ok := decoded.(*txtypes.L2CancelAllOrdersTxInfo); ok {
+		if err := checkCancelAll(t, now); err != nil {
+			return nil, err
+		}
+	}
+
+	nonces[ref] = h.Nonce + 1
+	if t, ok := decoded.(*txtypes.L2ChangePubKeyTxInfo); ok {
+		keys[keyRef{h.AccountIndex, t.ApiKeyIndex}] = pubKey
+	}
+	return &pending{
+		tx: Tx{
+			Hash:         hex.EncodeToString(msgHash),
+			Type:         txType,
+			Info:         txInfo,
+			AccountIndex: h.AccountIndex,
+			ApiKeyIndex:  h.ApiKeyIndex,
+			Nonce:        h.Nonce,
+			Time:         now,
+		},
+		acc:     acc,
+		decoded: decoded,
+		pubKey:  pubKey,
+	}, nil
+}
+
+// checkL1 verifies the owner signature of a ChangePubKey. An account
+// without an L1 address has no owner to authorize one.
+func checkL1(acc *account, tx *txtypes.L2ChangePubKeyTxInfo) error {
+	if acc.l1Address == "" {
+		return fmt.Errorf("account %d has no L1 address to authorize a key change", acc.index)
+	}
+	sig, err := signing.DecodeKey(tx.L1Sig)
+	if err != nil || len(sig) == 0 {
+		return fmt.Errorf("missing or invalid L1 signature")
+	}
+	address, err := signing.RecoverL1Address(tx.GetL1SignatureBody(), sig)
+	if err != nil {
+		return fmt.Errorf("invalid L1 signature: %v", err)
+	}
+	if !strings.EqualFold(address.Hex(), acc.l1Address) {
+		return fmt.Errorf("L1 signature is from %s, not the account owner", address.Hex())
+	}
+	return nil
+}
+
+// checkCancelAll rejects an unknown time in force and a scheduled
+// cancel-all whose time has passed
+func checkCancelAll(tx *txtypes.L2CancelAllOrdersTxInfo, now time.Time) error {
+	switch tx.TimeInForce {
+	case cancelAllImmediate, cancelAllAbort:
+		return nil
+	case cancelAllScheduled:
+		if tx.Time <= now.UnixMilli() {
+			return fmt.Errorf("scheduled cancel time %d is not in the future", tx.Time)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid cancel all time in force %d", tx.TimeInForce)
+}
+
+// execute applies an accepted tx and publishes what it changed
+func (s *Server) execute(p *pending, now time.Time) {
+	acc := p.acc
+	ch := newChanges(now, p.tx.Hash)
+
+	switch t := p.decoded.(type) {
+	case *txtypes.L2ChangePubKeyTxInfo:
+		acc.key(t.ApiKeyIndex).publicKey = append([]byte(nil), p.pubKey...)
+
+	case *txtypes.L2CreateOrderTxInfo:
+		if t.OrderInfo != nil {
+			s.place(s.newOrder(acc, t.OrderInfo, p.tx, now), ch)
+		}
+
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		orders := make([]*order, len(t.Orders))
+		for i, info := range t.Orders {
+			orders[i] = s.newOrder(acc, info, p.tx, now)
+		}
+		s.group(t.GroupingType, orders, ch)
+
+	case *txtypes.L2CancelOrderTxInfo:
+		if o := acc.findOrder(t.MarketIndex, t.Index); o != nil {
+			s.closeOrder(o, statusCanceled, ch)
+		}
+
+	case *txtypes.L2ModifyOrderTxInfo:
+		if o := acc.findOrder(t.MarketIndex, t.Index); o != nil {
+			s.modify(o, t, p.tx, ch)
+		}
+
+	case *txtypes.L2CancelAllOrdersTxInfo:
+		// A scheduled cancel replaces the pending one and runs in
+		// advance; an abort only clears it
+		switch t.TimeInForce {
+		case cancelAllImmediate:
+			s.cancelAll(acc, ch)
+		case cancelAllScheduled:
+			acc.cancelAt = t.Time
+		case cancelAllAbort:
+			acc.cancelAt = 0
+		}
+	}
+
+	s.settle(ch)
+	s.publishChanges(ch)
+}
+
+// cancelAll closes every active order of acc
+func (s *Server) cancelAll(acc *account, ch *changes) {
+	for _, o := range acc.sortedOrders() {
+		s.closeOrder(o, statusCanceled, ch)
+	}
+}
@@ -0,0 +1,112 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+func TestCheckCancelAll(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	tests := []struct {
+		name string
+		tif  uint8
+		at   int64
+		ok   bool
+	}{
+		{"immediate", cancelAllImmediate, 0, true},
+		{"scheduled in the future", cancelAllScheduled, now.UnixMilli() + 1, true},
+		{"scheduled now", cancelAllScheduled, now.UnixMilli(), false},
+		{"abort", cancelAllAbort, 0, true},
+		{"unknown time in force", 3, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCancelAll(&txtypes.L2CancelAllOrdersTxInfo{TimeInForce: tt.tif, Time: tt.at}, now)
+			if (err == nil) != tt.ok {
+				t.Errorf("checkCancelAll = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	at := func(d time.Duration) int64 {
+		return start.Add(d).UnixMilli()
+	}
+
+	type check struct {
+		after  time.Duration
+		status string
+	}
+	tests := []struct {
+		name   string
+		txs    []txtypes.L2CancelAllOrdersTxInfo
+		checks []check
+	}{
+		{
+			name:   "immediate",
+			txs:    []txtypes.L2CancelAllOrdersTxInfo{{TimeInForce: cancelAllImmediate}},
+			checks: []check{{0, statusCanceled}},
+		},
+		{
+			name:   "scheduled",
+			txs:    []txtypes.L2CancelAllOrdersTxInfo{{TimeInForce: cancelAllScheduled, Time: at(time.Minute)}},
+			checks: []check{{0, statusOpen}, {59 * time.Second, statusOpen}, {time.Minute, statusCanceled}},
+		},
+		{
+			name: "abort clears a scheduled cancel",
+			txs: []txtypes.L2CancelAllOrdersTxInfo{
+				{TimeInForce: cancelAllScheduled, Time: at(time.Minute)},
+				{TimeInForce: cancelAllAbort},
+			},
+			checks: []check{{0, statusOpen}, {2 * time.Minute, statusOpen}},
+		},
+		{
+			name:   "abort without a scheduled cancel",
+			txs:    []txtypes.L2CancelAllOrdersTxInfo{{TimeInForce: cancelAllAbort}},
+			checks: []check{{0, statusOpen}, {time.Hour, statusOpen}},
+		},
+		{
+			name: "later schedule replaces the earlier one",
+			txs: []txtypes.L2CancelAllOrdersTxInfo{
+				{TimeInForce: cancelAllScheduled, Time: at(time.Minute)},
+				{TimeInForce: cancelAllScheduled, Time: at(2 * time.Minute)},
+			},
+			checks: []check{{90 * time.Second, statusOpen}, {2 * time.Minute, statusCanceled}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			now := start
+			s.Now = func() time.Time { return now }
+
+			orders := s.placeTest(bid(1, 100, 1), ask(1, 110, 1))
+			other := s.placeTest(bid(2, 99, 1))[0]
+
+			s.mu.Lock()
+			for i := range tt.txs {
+				s.execute(&pending{acc: s.accounts[1], decoded: &tt.txs[i]}, now)
+			}
+			s.mu.Unlock()
+
+			for _, c := range tt.checks {
+				now = start.Add(c.after)
+				s.Advance()
+				for i, o := range orders {
+					if o.Status != c.status {
+						t.Errorf("after %v: order %d %s, want %s", c.after, i, o.Status, c.status)
+					}
+				}
+				if other.Status != statusOpen {
+					t.Errorf("after %v: other account's order %s", c.after, other.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"syscall/js"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// nonceKey identifies one nonce sequence. Lighter tracks nonces per API key
+// of an account.
+type nonceKey struct {
+	accountIndex int64
+	apiKeyIndex  uint8
+}
+
+// nonceState is the local view of one sequence: next is the nonce the next
+// reservation hands out, pending holds nonces reserved but not yet
+// committed or rolled back, and committed is the highest nonce known to be
+// used, starting below the seeded nextNonce.
+type nonceState struct {
+	next      int64
+	committed int64
+	pending   map[int64]struct{}
+}
+
+func newNonceState(next int64) *nonceState {
+	return &nonceState{next: next, committed: next - 1, pending: make(map[int64]struct{})}
+}
+
+var (
+	noncesMu sync.Mutex
+	nonces   = make(map[nonceKey]*nonceState)
+)
+
+// Parameters of the nonce exports besides the sequence key
+type (
+	seedNonceParams struct {
+		NextNonce int64 `param:"nextNonce,min=0"`
+	}
+
+	reserveNonceParams struct {
+		Count int64 `param:"count,optional,default=1,min=1,max=1000"`
+	}
+
+	settleNonceParams struct {
+		Nonce int64 `param:"nonce,min=0"`
+	}
+)
+
+// nonceKeyFromParams takes the sequence from a session handle if present,
+// otherwise from accountIndex and apiKeyIndex. No key material is needed.
+func nonceKeyFromParams(params js.Value) (nonceKey, error) {
+	handle := params.Get("session")
+	if handle.Type() == js.TypeString {
+		sessionsMu.Lock()
+		defer sessionsMu.Unlock()
+
+		s, ok := sessions[handle.String()]
+		if !ok {
+			return nonceKey{}, signing.Errorf(errcodes.InvalidKey, "session", nil, "Unknown or closed session")
+		}
+		return nonceKey{s.AccountIndex, s.ApiKeyIndex}, nil
+	}
+
+	var p struct {
+		AccountIndex int64 `param:"accountIndex,min=0"`
+		ApiKeyIndex  uint8 `param:"apiKeyIndex"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nonceKey{}, err
+	}
+	return nonceKey{p.AccountIndex, p.ApiKeyIndex}, nil
+}
+
+// seed starts a sequence at the server's nextNonce unless it is already
+// tracked; use resync to overwrite a tracked sequence.
+func (k nonceKey) seed(next int64) *nonceState {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	if st, ok := nonces[k]; ok {
+		return st
+	}
+	st := newNonceState(next)
+	nonces[k] = st
+	return st
+}
+
+// resync replaces the sequence with the server's nextNonce and forgets
+// every pending reservation.
+func (k nonceKey) resync(next int64) {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	nonces[k] = newNonceState(next)
+}
+
+// reserve hands out count consecutive nonces and returns the first
+func (k nonceKey) reserve(count int64) (int64, error) {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	st, ok := nonces[k]
+	if !ok {
+		return 0, k.unseeded()
+	}
+
+	start := st.next
+	for n := start; n < start+count; n++ {
+		st.pending[n] = struct{}{}
+	}
+	st.next += count
+	return start, nil
+}
+
+// commit marks a reserved nonce as accepted by the exchange. It reports
+// false if the nonce was not pending.
+func (k nonceKey) commit(nonce int64) (bool, error) {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	st, ok := nonces[k]
+	if !ok {
+		return false, k.unseeded()
+	}
+
+	if _, ok := st.pending[nonce]; !ok {
+		return false, nil
+	}
+	delete(st.pending, nonce)
+	if nonce > st.committed {
+		st.committed = nonce
+	}
+	return true, nil
+}
+
+// rollback returns a reserved nonce whose tx was never accepted. The
+// exchange only takes nonces in sequence, so every reservation after it is
+// dropped as well and the next reservation starts again at nonce. If a
+// later nonce was already committed the local sequence is wrong, and
+// rollback fails with NonceStale instead of rewinding past it.
+func (k nonceKey) rollback(nonce int64) (bool, error) {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	st, ok := nonces[k]
+	if !ok {
+		return false, k.unseeded()
+	}
+
+	if _, ok := st.pending[nonce]; !ok {
+		return false, nil
+	}
+	if st.committed > nonce {
+		return false, signing.Errorf(errcodes.NonceStale, "nonce", nil, "Cannot roll back nonce %d: nonce %d was already committed, resync the sequence", nonce, st.committed)
+	}
+	for n := range st.pending {
+		if n >= nonce {
+			delete(st.pending, n)
+		}
+	}
+	st.next = nonce
+	return true, nil
+}
+
+func (k nonceKey) unseeded() error {
+	return signing.Errorf(errcodes.NonceNotSeeded, "", nil, "Nonce manager not seeded for account %d api key %d", k.accountIndex, k.apiKeyIndex)
+}
+
+// status describes the sequence as a plain object
+func (k nonceKey) status() map[string]interface{} {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	st, ok := nonces[k]
+	if !ok {
+		return map[string]interface{}{"seeded": false}
+	}
+
+	pending := make([]int64, 0, len(st.pending))
+	for n := range st.pending {
+		pending = append(pending, n)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i] < pending[j] })
+
+	list := make([]interface{}, len(pending))
+	for i, n := range pending {
+		list[i] = n
+	}
+	return map[string]interface{}{
+		"seeded":    true,
+		"nextNonce": st.next,
+		"pending":   list,
+	}
+}
+
+// nonceCall parses the sequence key and, unless p is nil, the call's own
+// parameters into p
+func nonceCall(args []js.Value, p interface{}) (nonceKey, error) {
+	if len(args) < 1 {
+		return nonceKey{}, fmt.Errorf("Missing arguments: no parameters provided")
+	}
+
+	key, err := nonceKeyFromParams(args[0])
+	if err != nil {
+		return nonceKey{}, err
+	}
+
+	if p != nil {
+		if err := decodeParams(args[0], p); err != nil {
+			return nonceKey{}, err
+		}
+	}
+	return key, nil
+}
+
+// seedNonce starts tracking a sequence from the server's nextNonce:
+// seedNonce({session or accountIndex/apiKeyIndex, nextNonce}). A sequence
+// that is already tracked is left alone; resolves its status either way.
+func seedNonce(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		var p seedNonceParams
+		key, err := nonceCall(args, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		key.seed(p.NextNonce)
+		return key.status(), nil
+	})
+}
+
+// resyncNonce overwrites a sequence with the server's nextNonce, e.g. after
+// the exchange rejected a tx for an invalid nonce.
+func resyncNonce(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		var p seedNonceParams
+		key, err := nonceCall(args, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		key.resync(p.NextNonce)
+		return key.status(), nil
+	})
+}
+
+// reserveNonce hands out count (default 1) consecutive nonces and resolves
+// {nonce, count} with the first of them.
+func reserveNonce(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		var p reserveNonceParams
+		key, err := nonceCall(args, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		count := p.Count
+		nonce, err := key.reserve(count)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"nonce": nonce,
+			"count": count,
+		}, nil
+	})
+}
+
+// commitNonce marks a reserved nonce as used. Resolves false if it was not
+// pending.
+func commitNonce(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		var p settleNonceParams
+		key, err := nonceCall(args, &p)
+		if err != nil {
+			return nil, err
+		}
+		return key.commit(p.Nonce)
+	})
+}
+
+// rollbackNonce releases a reserved nonce after a failed sign or submit.
+// Resolves false if it was not pending; rejects with NONCE_STALE if a later
+// nonce was committed, in which case the caller should resyncNonce.
+func rollbackNonce(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		var p settleNonceParams
+		key, err := nonceCall(args, &p)
+		if err != nil {
+			return nil, err
+		}
+		return key.rollback(p.Nonce)
+	})
+}
+
+// nonceStatus resolves {seeded, nextNonce, pending} for a sequence
+func nonceStatus(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		key, err := nonceCall(args, nil)
+		if err != nil {
+			return nil, err
+		}
+		return key.status(), nil
+	})
+}
@@ -0,0 +1,276 @@
+// Package orderbook maintains local order books from order_book stream
+// deltas. Writers apply updates to a private copy and publish it as an
+// immutable Snapshot, so strategy goroutines read without locking.
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"lighter-wasm/stream"
+)
+
+// ErrGap means updates were missed or the book became inconsistent; the
+// book is stale until the next snapshot
+var ErrGap = errors.New("order book gap")
+
+// Side selects the bid or ask side of a book
+type Side int
+
+const (
+	Bid Side = iota
+	Ask
+)
+
+// Level is the total size resting at one price, both in integer units of
+// the market's price and size decimals
+type Level struct {
+	Price int64
+	Size  int64
+}
+
+// Market is the metadata needed to interpret a market's price levels
+type Market struct {
+	Id            uint8
+	Symbol        string
+	PriceDecimals int
+	SizeDecimals  int
+}
+
+// ParsePrice converts a decimal price string to integer units
+func (m *Market) ParsePrice(s string) (int64, error) {
+	return parseScaled(s, m.PriceDecimals)
+}
+
+// ParseSize converts a decimal size string to integer units
+func (m *Market) ParseSize(s string) (int64, error) {
+	return parseScaled(s, m.SizeDecimals)
+}
+
+// FormatPrice converts integer price units back to a decimal string
+func (m *Market) FormatPrice(price int64) string {
+	return formatScaled(price, m.PriceDecimals)
+}
+
+// FormatSize converts integer size units back to a decimal string
+func (m *Market) FormatSize(size int64) string {
+	return formatScaled(size, m.SizeDecimals)
+}
+
+// Book is the local order book of one market. Apply and Reset must be
+// called from one goroutine at a time; Snapshot may be called from any.
+type Book struct {
+	market Market
+	mu     sync.Mutex
+	snap   atomic.Pointer[Snapshot]
+}
+
+// NewBook returns an empty, stale book for market
+func NewBook(market Market) *Book {
+	b := &Book{market: market}
+	b.snap.Store(&Snapshot{Market: market, Stale: true})
+	return b
+}
+
+// Snapshot returns the current state of the book without locking. The
+// returned value and its slices must not be modified.
+func (b *Book) Snapshot() *Snapshot {
+	return b.snap.Load()
+}
+
+// Reset replaces the book with a full snapshot. bids and asks may be in
+// any order and may repeat prices, which are summed.
+func (b *Book) Reset(bids, asks []Level, nonce, offset, timestamp int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := b.snap.Load()
+	b.snap.Store(&Snapshot{
+		Market:      b.market,
+		Bids:        normalize(bids, Bid),
+		Asks:        normalize(asks, Ask),
+		Nonce:       nonce,
+		Offset:      offset,
+		Timestamp:   timestamp,
+		Updates:     prev.Updates + 1,
+		Resnapshots: prev.Resnapshots,
+	})
+}
+
+// Apply applies an order_book stream message. A subscribed/ message is a
+// full snapshot; an update/ message holds changed levels, a size of zero
+// removing the level. Messages older than the current offset are ignored.
+// It returns ErrGap, leaving the book marked stale, when BeginNonce does
+// not continue the previous Nonce or the result would be crossed.
+func (b *Book) Apply(u *stream.OrderBookUpdate) error {
+	bids, err := b.parseLevels(u.OrderBook.Bids)
+	if err != nil {
+		return err
+	}
+	asks, err := b.parseLevels(u.OrderBook.Asks)
+	if err != nil {
+		return err
+	}
+	offset := u.Offset
+	if offset == 0 {
+		offset = u.OrderBook.Offset
+	}
+
+	if u.IsSnapshot() {
+		b.Reset(bids, asks, u.OrderBook.Nonce, offset, u.Timestamp)
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := b.snap.Load()
+	if prev.Stale {
+		// Waiting for a snapshot; deltas cannot be placed
+		return nil
+	}
+	if offset != 0 && offset <= prev.Offset {
+		return nil
+	}
+	if u.OrderBook.BeginNonce != 0 && prev.Nonce != 0 && u.OrderBook.BeginNonce != prev.Nonce {
+		b.markStale(prev)
+		return fmt.Errorf("%w: market %d expected begin nonce %d, got %d", ErrGap, b.market.Id, prev.Nonce, u.OrderBook.BeginNonce)
+	}
+
+	next := &Snapshot{
+		Market:      b.market,
+		Bids:        applyLevels(prev.Bids, bids, Bid),
+		Asks:        applyLevels(prev.Asks, asks, Ask),
+		Nonce:       u.OrderBook.Nonce,
+		Offset:      offset,
+		Timestamp:   u.Timestamp,
+		Updates:     prev.Updates + 1,
+		Resnapshots: prev.Resnapshots,
+	}
+	if len(next.Bids) > 0 && len(next.Asks) > 0 && next.Bids[0].Price >= next.Asks[0].Price {
+		b.markStale(prev)
+		return fmt.Errorf("%w: market %d crossed at bid %s, ask %s", ErrGap, b.market.Id,
+			b.market.FormatPrice(next.Bids[0].Price), b.market.FormatPrice(next.Asks[0].Price))
+	}
+	b.snap.Store(next)
+	return nil
+}
+
+// markStale must be called with mu held
+func (b *Book) markStale(prev *Snapshot) {
+	stale := *prev
+	stale.Stale = true
+	stale.Resnapshots++
+	b.snap.Store(&stale)
+}
+
+func (b *Book) parseLevels(levels []stream.PriceLevel) ([]Level, error) {
+	out := make([]Level, len(levels))
+	for i, l := range levels {
+		price, err := b.market.ParsePrice(l.Price)
+		if err != nil {
+			return nil, fmt.Errorf("market %d: invalid price %q: %v", b.market.Id, l.Price, err)
+		}
+		size, err := b.market.ParseSize(l.Size)
+		if err != nil {
+			return nil, fmt.Errorf("market %d: invalid size %q: %v", b.market.Id, l.Size, err)
+		}
+		out[i] = Level{price, size}
+	}
+	return out, nil
+}
+
+// better reports whether price a ranks before b on side
+func better(side Side, a, b int64) bool {
+	if side == Bid {
+		return a > b
+	}
+	return a < b
+}
+
+// normalize sorts levels best first, sums repeated prices and drops empty
+// levels
+func normalize(levels []Level, side Side) []Level {
+	sorted := append([]Level(nil), levels...)
+	sort.Slice(sorted, func(i, j int) bool { return better(side, sorted[i].Price, sorted[j].Price) })
+
+	out := sorted[:0]
+	for _, l := range sorted {
+		if n := len(out); n > 0 && out[n-1].Price == l.Price {
+			out[n-1].Size += l.Size
+			continue
+		}
+		out = append(out, l)
+	}
+	kept := out[:0]
+	for _, l := range out {
+		if l.Size > 0 {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
+
+// applyLevels returns a copy of book, sorted best first, with each delta
+// level set to its new size
+func applyLevels(book, deltas []Level, side Side) []Level {
+	if len(deltas) == 0 {
+		return book
+	}
+
+	out := append(make([]Level, 0, len(book)+len(deltas)), book...)
+	for _, d := range deltas {
+		i := sort.Search(len(out), func(i int) bool { return !better(side, out[i].Price, d.Price) })
+		found := i < len(out) && out[i].Price == d.Price
+		switch {
+		case found && d.Size == 0:
+			out = append(out[:i], out[i+1:]...)
+		case found:
+			out[i].Size = d.Size
+		case d.Size > 0:
+			out = append(out, Level{})
+			copy(out[i+1:], out[i:])
+			out[i] = d
+		}
+	}
+	return out
+}
+
+// parseScaled parses a non-negative decimal string into an integer with
+// decimals fractional digits. Extra fractional digits must be zero.
+func parseScaled(s string, decimals int) (int64, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if extra := len(frac) - decimals; extra > 0 {
+		if strings.Trim(frac[decimals:], "0") != "" {
+			return 0, fmt.Errorf("more than %d decimals", decimals)
+		}
+		frac = frac[:decimals]
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+
+	digits := whole + frac
+	if digits == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+	return strconv.ParseInt(digits, 10, 64)
+}
+
+func formatScaled(n int64, decimals int) string {
+	if decimals == 0 {
+		return strconv.FormatInt(n, 10)
+	}
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	s := strconv.FormatInt(n, 10)
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	return sign + s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+}
@@ -0,0 +1,165 @@
+package orderbook
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"lighter-wasm/stream"
+)
+
+var testMarket = Market{Id: 1, Symbol: "ETH", PriceDecimals: 2, SizeDecimals: 1}
+
+func update(kind string, beginNonce, nonce, offset int64, bids, asks []stream.PriceLevel) *stream.OrderBookUpdate {
+	return &stream.OrderBookUpdate{
+		Header: stream.Header{Type: kind + "/order_book", Channel: "order_book/1"},
+		Offset: offset,
+		OrderBook: stream.OrderBookState{
+			Bids:       bids,
+			Asks:       asks,
+			Nonce:      nonce,
+			BeginNonce: beginNonce,
+		},
+	}
+}
+
+func levels(pairs ...string) []stream.PriceLevel {
+	var out []stream.PriceLevel
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, stream.PriceLevel{Price: pairs[i], Size: pairs[i+1]})
+	}
+	return out
+}
+
+func TestBookApply(t *testing.T) {
+	// Every case starts from bids 100.00x2.0, 99.50x1.0 and asks
+	// 101.00x3.0, 102.00x1.5 at nonce 10, offset 5
+	snapshot := update("subscribed", 0, 10, 5,
+		levels("99.5", "1", "100", "2"),
+		levels("102", "1.5", "101", "3"),
+	)
+
+	tests := []struct {
+		name    string
+		update  *stream.OrderBookUpdate
+		gap     bool
+		stale   bool
+		nonce   int64
+		bids    []Level
+		asks    []Level
+		updates int64
+	}{
+		{
+			name:    "adds and changes levels",
+			update:  update("update", 10, 11, 6, levels("100.25", "0.5", "99.5", "4"), levels("101", "1")),
+			nonce:   11,
+			bids:    []Level{{10025, 5}, {10000, 20}, {9950, 40}},
+			asks:    []Level{{10100, 10}, {10200, 15}},
+			updates: 2,
+		},
+		{
+			name:    "zero size removes a level",
+			update:  update("update", 10, 11, 6, levels("100", "0"), levels("102", "0.0")),
+			nonce:   11,
+			bids:    []Level{{9950, 10}},
+			asks:    []Level{{10100, 30}},
+			updates: 2,
+		},
+		{
+			name:    "old offset is ignored",
+			update:  update("update", 10, 11, 5, levels("100", "0"), nil),
+			nonce:   10,
+			bids:    []Level{{10000, 20}, {9950, 10}},
+			asks:    []Level{{10100, 30}, {10200, 15}},
+			updates: 1,
+		},
+		{
+			name:    "begin nonce gap",
+			update:  update("update", 9, 11, 6, levels("100.25", "0.5"), nil),
+			gap:     true,
+			stale:   true,
+			nonce:   10,
+			bids:    []Level{{10000, 20}, {9950, 10}},
+			asks:    []Level{{10100, 30}, {10200, 15}},
+			updates: 1,
+		},
+		{
+			name:    "bid at the best ask crosses",
+			update:  update("update", 10, 11, 6, levels("101", "1"), nil),
+			gap:     true,
+			stale:   true,
+			nonce:   10,
+			bids:    []Level{{10000, 20}, {9950, 10}},
+			asks:    []Level{{10100, 30}, {10200, 15}},
+			updates: 1,
+		},
+		{
+			name:    "ask below the best bid crosses",
+			update:  update("update", 10, 11, 6, nil, levels("99.75", "1")),
+			gap:     true,
+			stale:   true,
+			nonce:   10,
+			bids:    []Level{{10000, 20}, {9950, 10}},
+			asks:    []Level{{10100, 30}, {10200, 15}},
+			updates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBook(testMarket)
+			if err := b.Apply(snapshot); err != nil {
+				t.Fatalf("snapshot: %v", err)
+			}
+
+			err := b.Apply(tt.update)
+			if got := errors.Is(err, ErrGap); got != tt.gap || err != nil && !got {
+				t.Fatalf("Apply error = %v, want gap %v", err, tt.gap)
+			}
+
+			snap := b.Snapshot()
+			if snap.Stale != tt.stale {
+				t.Errorf("Stale = %v, want %v", snap.Stale, tt.stale)
+			}
+			if snap.Nonce != tt.nonce {
+				t.Errorf("Nonce = %d, want %d", snap.Nonce, tt.nonce)
+			}
+			if !reflect.DeepEqual(snap.Bids, tt.bids) {
+				t.Errorf("Bids = %v, want %v", snap.Bids, tt.bids)
+			}
+			if !reflect.DeepEqual(snap.Asks, tt.asks) {
+				t.Errorf("Asks = %v, want %v", snap.Asks, tt.asks)
+			}
+			if snap.Updates != tt.updates {
+				t.Errorf("Updates = %d, want %d", snap.Updates, tt.updates)
+			}
+		})
+	}
+}
+
+func TestBookResnapshotAfterGap(t *testing.T) {
+	b := NewBook(testMarket)
+	if err := b.Apply(update("update", 0, 1, 1, levels("100", "1"), nil)); err != nil {
+		t.Fatalf("update before snapshot: %v", err)
+	}
+	if snap := b.Snapshot(); !snap.Stale || len(snap.Bids) != 0 {
+		t.Fatalf("update before snapshot applied: %+v", snap)
+	}
+
+	b.Apply(update("subscribed", 0, 10, 5, levels("100", "1"), levels("101", "1")))
+	if err := b.Apply(update("update", 8, 12, 6, nil, levels("101", "2"))); !errors.Is(err, ErrGap) {
+		t.Fatalf("gap not detected: %v", err)
+	}
+	if err := b.Apply(update("update", 12, 13, 7, nil, levels("101", "3"))); err != nil {
+		t.Fatalf("update while stale: %v", err)
+	}
+	if snap := b.Snapshot(); snap.Asks[0].Size != 10 {
+		t.Fatalf("update while stale applied: %+v", snap.Asks)
+	}
+
+	b.Apply(update("subscribed", 0, 20, 9, levels("100", "1"), levels("101", "4")))
+	snap := b.Snapshot()
+	if snap.Stale || snap.Resnapshots != 1 || snap.Asks[0].Size != 40 {
+		t.Fatalf("after resnapshot: %+v", snap)
+	}
+}
@@ -0,0 +1,117 @@
+package orderbook
+
+import (
+	"sort"
+)
+
+// Snapshot is an immutable state of a Book. Bids are sorted highest price
+// first and Asks lowest price first.
+type Snapshot struct {
+	Market Market
+	Bids   []Level
+	Asks   []Level
+	// Nonce and Offset are those of the last applied message; both are zero
+	// for a book seeded over REST
+	Nonce     int64
+	Offset    int64
+	Timestamp int64
+	// Stale is set until the first snapshot and after a gap, until the
+	// resnapshot arrives
+	Stale bool
+	// Updates counts applied messages and Resnapshots detected gaps
+	Updates     int64
+	Resnapshots int64
+}
+
+func (s *Snapshot) side(side Side) []Level {
+	if side == Bid {
+		return s.Bids
+	}
+	return s.Asks
+}
+
+// BestBid returns the highest bid
+func (s *Snapshot) BestBid() (Level, bool) {
+	if len(s.Bids) == 0 {
+		return Level{}, false
+	}
+	return s.Bids[0], true
+}
+
+// BestAsk returns the lowest ask
+func (s *Snapshot) BestAsk() (Level, bool) {
+	if len(s.Asks) == 0 {
+		return Level{}, false
+	}
+	return s.Asks[0], true
+}
+
+// Spread returns the best ask minus the best bid
+func (s *Snapshot) Spread() (int64, bool) {
+	bid, okBid := s.BestBid()
+	ask, okAsk := s.BestAsk()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// Mid returns the midpoint of the best bid and ask, rounded down
+func (s *Snapshot) Mid() (int64, bool) {
+	bid, okBid := s.BestBid()
+	ask, okAsk := s.BestAsk()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return bid.Price + (ask.Price-bid.Price)/2, true
+}
+
+// Depth returns up to n levels of each side, best first. The slices share
+// the snapshot's storage.
+func (s *Snapshot) Depth(n int) (bids, asks []Level) {
+	return head(s.Bids, n), head(s.Asks, n)
+}
+
+func head(levels []Level, n int) []Level {
+	if n < 0 || n > len(levels) {
+		n = len(levels)
+	}
+	return levels[:n:n]
+}
+
+// CumulativeSize returns the total size of the best n levels of side
+func (s *Snapshot) CumulativeSize(side Side, n int) int64 {
+	var total int64
+	for _, l := range head(s.side(side), n) {
+		total += l.Size
+	}
+	return total
+}
+
+// SizeToPrice returns the total size of side resting at price or better,
+// i.e. what a taker could fill up to that limit price
+func (s *Snapshot) SizeToPrice(side Side, price int64) int64 {
+	levels := s.side(side)
+	n := sort.Search(len(levels), func(i int) bool { return better(side, price, levels[i].Price) })
+	var total int64
+	for _, l := range levels[:n] {
+		total += l.Size
+	}
+	return total
+}
+
+// PriceForSize walks side from the best level and returns the worst price
+// needed to fill size, and the size actually available up to it
+func (s *Snapshot) PriceForSize(side Side, size int64) (price, filled int64) {
+	for _, l := range s.side(side) {
+		if filled >= size {
+			break
+		}
+		price = l.Price
+		filled += l.Size
+	}
+	if filled > size {
+		filled = size
+	}
+	return price, filled
+}
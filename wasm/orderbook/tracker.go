@@ -0,0 +1,147 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"lighter-wasm/api"
+	"lighter-wasm/stream"
+)
+
+// SeedLimit is the number of resting orders per side fetched to seed a book
+const SeedLimit = 250
+
+// MarketOf converts REST order book details to a Market
+func MarketOf(d *api.OrderBookDetail) Market {
+	return Market{
+		Id:            d.MarketId,
+		Symbol:        d.Symbol,
+		PriceDecimals: d.PriceDecimals,
+		SizeDecimals:  d.SizeDecimals,
+	}
+}
+
+// Tracker keeps books of several markets in sync with a stream client. A
+// gap marks the book stale and resubscribes its channel so the server
+// sends a fresh snapshot.
+type Tracker struct {
+	API    *api.Client
+	Stream *stream.Client
+	// OnError receives gaps and invalid messages; nil ignores them
+	OnError func(error)
+
+	mu    sync.Mutex
+	books map[uint8]*Book
+}
+
+// NewTracker returns a tracker that seeds books over apiClient and updates
+// them from streamClient, whose Run the caller drives
+func NewTracker(apiClient *api.Client, streamClient *stream.Client) *Tracker {
+	return &Tracker{
+		API:    apiClient,
+		Stream: streamClient,
+		books:  make(map[uint8]*Book),
+	}
+}
+
+// Track fetches the market's details, seeds its book from the resting
+// orders and subscribes to its deltas. Tracking a market twice returns the
+// same book.
+func (t *Tracker) Track(ctx context.Context, marketId uint8) (*Book, error) {
+	if b := t.Book(marketId); b != nil {
+		return b, nil
+	}
+
+	details, err := t.API.GetOrderBookDetails(ctx, marketId)
+	if err != nil {
+		return nil, err
+	}
+	if len(details.OrderBookDetails) == 0 {
+		return nil, fmt.Errorf("market %d not found", marketId)
+	}
+	b := NewBook(MarketOf(&details.OrderBookDetails[0]))
+	if err := t.seed(ctx, b); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	if existing, ok := t.books[marketId]; ok {
+		t.mu.Unlock()
+		return existing, nil
+	}
+	t.books[marketId] = b
+	t.mu.Unlock()
+
+	t.Stream.SubscribeOrderBook(marketId, func(u *stream.OrderBookUpdate) {
+		t.apply(b, u)
+	})
+	return b, nil
+}
+
+// Book returns the book of a tracked market, or nil
+func (t *Tracker) Book(marketId uint8) *Book {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.books[marketId]
+}
+
+// Untrack unsubscribes a market and forgets its book
+func (t *Tracker) Untrack(marketId uint8) {
+	t.mu.Lock()
+	_, ok := t.books[marketId]
+	delete(t.books, marketId)
+	t.mu.Unlock()
+
+	if ok {
+		t.Stream.Unsubscribe(channel(marketId))
+	}
+}
+
+// seed resets b from the REST resting orders, summed per price
+func (t *Tracker) seed(ctx context.Context, b *Book) error {
+	orders, err := t.API.GetOrderBookOrders(ctx, b.market.Id, SeedLimit)
+	if err != nil {
+		return err
+	}
+	bids, err := b.bookLevels(orders.Bids)
+	if err != nil {
+		return err
+	}
+	asks, err := b.bookLevels(orders.Asks)
+	if err != nil {
+		return err
+	}
+	b.Reset(bids, asks, 0, 0, 0)
+	return nil
+}
+
+func (t *Tracker) apply(b *Book, u *stream.OrderBookUpdate) {
+	err := b.Apply(u)
+	if err == nil {
+		return
+	}
+	t.report(err)
+	if errors.Is(err, ErrGap) {
+		t.Stream.Resubscribe(channel(b.market.Id))
+	}
+}
+
+func (t *Tracker) report(err error) {
+	if err != nil && t.OnError != nil {
+		t.OnError(err)
+	}
+}
+
+func channel(marketId uint8) string {
+	return fmt.Sprintf("%s/%d", stream.ChannelOrderBook, marketId)
+}
+
+func (b *Book) bookLevels(orders []api.BookOrder) ([]Level, error) {
+	levels := make([]stream.PriceLevel, len(orders))
+	for i, o := range orders {
+		levels[i] = stream.PriceLevel{Price: o.Price, Size: o.RemainingBaseAmount}
+	}
+	return b.parseLevels(levels)
+}
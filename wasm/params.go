@@ -0,0 +1,143 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"syscall/js"
+
+	"lighter-wasm/signing"
+)
+
+// maxSafeInteger is Number.MAX_SAFE_INTEGER. JS numbers beyond it have
+// already lost precision by the time they reach Go.
+const maxSafeInteger = 1<<53 - 1
+
+// paramError is the structured rejection for a parameter that fails its
+// param tag. It reaches JavaScript with code INVALID_PARAM, reason and
+// field.
+type paramError = signing.ParamError
+
+const (
+	reasonMissing = signing.ReasonMissing
+	reasonType    = signing.ReasonType
+	reasonRange   = signing.ReasonRange
+)
+
+// jsTypeOf exposes the typeof operator. js.Value.Type panics on BigInt, so
+// it cannot be used to tell the accepted input kinds apart.
+var jsTypeOf = js.Global().Get("Function").New("v", "return typeof v")
+
+func isArray(v js.Value) bool {
+	return js.Global().Get("Array").Call("isArray", v).Bool()
+}
+
+// parseInteger converts a JS number, BigInt or base-10 string to a big.Int
+// without routing anything through float64 that does not fit losslessly.
+func parseInteger(v js.Value) (*big.Int, error) {
+	var text string
+
+	switch kind := jsTypeOf.Invoke(v).String(); kind {
+	case "number":
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return nil, fmt.Errorf("%v is not an integer", f)
+		}
+		if math.Abs(f) > maxSafeInteger {
+			return nil, fmt.Errorf("%v exceeds Number.MAX_SAFE_INTEGER, pass a BigInt or decimal string", f)
+		}
+		return big.NewInt(int64(f)), nil
+	case "bigint":
+		text = js.Global().Get("String").Invoke(v).String()
+	case "string":
+		text = strings.TrimSpace(v.String())
+	default:
+		return nil, fmt.Errorf("expected number, BigInt or decimal string, got %s", kind)
+	}
+
+	n, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a base-10 integer", text)
+	}
+	return n, nil
+}
+
+// decodeParams fills req, a pointer to a signing request struct, from a JS
+// object following the struct's param tags, then runs signing.Check. Only
+// presence, JS types and Go type ranges are checked here; every other
+// constraint and default lives in the signing package.
+func decodeParams(params js.Value, req interface{}) error {
+	if perr := decodeStruct(params, reflect.ValueOf(req).Elem(), ""); perr != nil {
+		return perr
+	}
+	return signing.Check(req)
+}
+
+func decodeStruct(obj js.Value, v reflect.Value, prefix string) *paramError {
+	if jsTypeOf.Invoke(obj).String() != "object" || obj.IsNull() {
+		return &paramError{Reason: reasonType, Field: strings.TrimSuffix(prefix, "."), Message: "expected an object"}
+	}
+
+	for _, f := range signing.Fields(v.Type()) {
+		if perr := decodeField(obj.Get(f.Name), &f, v.FieldByIndex(f.Index), prefix+f.Name); perr != nil {
+			return perr
+		}
+	}
+	return nil
+}
+
+func decodeField(jv js.Value, f *signing.Field, v reflect.Value, path string) *paramError {
+	if jv.IsUndefined() || jv.IsNull() {
+		if !f.Optional {
+			return &paramError{Reason: reasonMissing, Field: path, Message: "is required"}
+		}
+		return nil
+	}
+
+	switch {
+	case f.IsInteger():
+		n, err := parseInteger(jv)
+		if err != nil {
+			return &paramError{Reason: reasonType, Field: path, Message: err.Error()}
+		}
+		if perr := f.CheckRange(n, path); perr != nil {
+			return perr
+		}
+		signing.SetInt(v, n)
+
+	case v.Kind() == reflect.String:
+		if jv.Type() != js.TypeString {
+			return &paramError{Reason: reasonType, Field: path, Message: "expected a string"}
+		}
+		v.SetString(jv.String())
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if jv.Type() != js.TypeString {
+			return &paramError{Reason: reasonType, Field: path, Message: "expected a hex string"}
+		}
+		b, err := hex.DecodeString(strings.TrimPrefix(jv.String(), "0x"))
+		if err != nil {
+			return &paramError{Reason: reasonType, Field: path, Message: fmt.Sprintf("invalid hex: %v", err)}
+		}
+		v.SetBytes(b)
+
+	case v.Kind() == reflect.Slice:
+		if !isArray(jv) {
+			return &paramError{Reason: reasonType, Field: path, Message: "expected an array"}
+		}
+		items := reflect.MakeSlice(v.Type(), jv.Length(), jv.Length())
+		for i := 0; i < items.Len(); i++ {
+			if perr := decodeStruct(jv.Index(i), items.Index(i), fmt.Sprintf("%s[%d].", path, i)); perr != nil {
+				return perr
+			}
+		}
+		v.Set(items)
+	}
+	return nil
+}
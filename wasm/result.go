@@ -0,0 +1,26 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "lighter-wasm/signing"
+
+// resultValue converts a signing.Result into the object sign* functions
+// resolve
+func resultValue(r *signing.Result) map[string]interface{} {
+	result := map[string]interface{}{
+		"txType":       int(r.TxType),
+		"txInfo":       r.TxInfo,
+		"txHash":       r.TxHash,
+		"nonce":        r.Nonce,
+		"expiredAt":    r.ExpiredAt,
+		"accountIndex": r.AccountIndex,
+		"apiKeyIndex":  int(r.ApiKeyIndex),
+	}
+
+	// The message the account owner personal_signs for attachL1Signature
+	if r.L1Message != "" {
+		result["l1Message"] = r.L1Message
+	}
+	return result
+}
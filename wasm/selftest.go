@@ -0,0 +1,271 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
+	poseidon2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"lighter-wasm/signing"
+)
+
+// Known-answer vectors, produced by poseidon_crypto v0.0.11. A build that
+// links a different hash or curve implementation (e.g. through a stray
+// replace directive) fails these before it can sign anything.
+const (
+	// HashToQuinticExtension of the field elements 0..7
+	katPoseidonHash = "ff2e86b62bf0565d2542f57c854ad38b7b722227e65b6f7b140ffbea25ae36d0e51521f8446dc30b"
+
+	// Private key 01 02 .. 27 00 (little-endian) and its public key
+	katPrivateKey = "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262700"
+	katPublicKey  = "02411468afeda59909544173034ff6f8cb6f69590e05afc649ec66c74e4f36a61991b65425a29791"
+
+	// Signature of HashToQuinticExtension(1, 2, 3) with nonce 41 42 .. 67 00
+	katNonce     = "4142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f606162636465666700"
+	katMessage   = "eba7ee38133f127ddaa119070a5c51d0c65bb35ab5c4b037df453847037f9081b13ba509cf216e2d"
+	katSignature = "66e0445bd56ca7af893abeee354d4149c5d196e0d234d503e62109b8a9bad173402bec90f176fc051e44dd4d70016f7f93a933ffe670b9de350815362de81c58c0f334466886d11b1ae061059949b663"
+)
+
+// katTxTime fixes the signing clock for the tx checks so they are
+// independent of the host clock
+var katTxTime = time.UnixMilli(1700000000000)
+
+// katTxs are signed with katPrivateKey as account 1, API key 3, nonce 7
+// and expiredAt katTxTime+DefaultExpiry on chain 304. The tx hash is fixed
+// by those inputs; lighter-go signs with a random nonce, so the signature
+// is only checked to verify under the expected public key and to fail for
+// any other tx.
+var katTxs = []struct {
+	txType uint8
+	params map[string]interface{}
+	hash   string
+}{
+	{txtypes.TxTypeL2CreateOrder, map[string]interface{}{
+		"marketIndex":      0,
+		"clientOrderIndex": 42,
+		"baseAmount":       1000,
+		"price":            300000,
+		"isAsk":            1,
+		"orderType":        0,
+		"timeInForce":      1,
+		"reduceOnly":       0,
+		"triggerPrice":     0,
+		"orderExpiry":      1702419200000,
+	}, "f3d907efedb052c63800f05406b8dead803318b79ae26a0e7ccb25ce5b6fa36cca4416065ca5689c"},
+	{txtypes.TxTypeL2Withdraw, map[string]interface{}{
+		"usdcAmount": 1000000,
+	}, "7d273df8774ccad4fc533a63c95633897d3d16b87a3bfcd8df535147613f27bc3309666179aa24ff"},
+}
+
+// selfTestCheck is the outcome of one known-answer check
+type selfTestCheck struct {
+	name string
+	err  error
+}
+
+// selfTestResults holds the checks run at startup; main only sets ready
+// if all of them passed
+var selfTestResults []selfTestCheck
+
+// runSelfTest runs every known-answer check. A panicking check is
+// reported as failed rather than taking the module down.
+func runSelfTest() []selfTestCheck {
+	checks := []struct {
+		name string
+		fn   func() error
+	}{
+		{"poseidon2", checkPoseidon},
+		{"schnorrPublicKey", checkPublicKey},
+		{"schnorrSign", checkSchnorrSign},
+	}
+	for _, tx := range katTxs {
+		tx := tx
+		checks = append(checks, struct {
+			name string
+			fn   func() error
+		}{"tx:" + txName(tx.txType), func() error { return checkTx(tx.txType, tx.params, tx.hash) }})
+	}
+
+	results := make([]selfTestCheck, len(checks))
+	for i, c := range checks {
+		results[i] = selfTestCheck{name: c.name, err: runCheck(c.fn)}
+	}
+	return results
+}
+
+func runCheck(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Panic: %v", r)
+		}
+	}()
+	return fn()
+}
+
+// selfTestPassed reports whether every check succeeded
+func selfTestPassed(results []selfTestCheck) bool {
+	for _, r := range results {
+		if r.err != nil {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+// expectHex compares bytes against a hex known answer
+func expectHex(what string, got []byte, want string) error {
+	if h := hex.EncodeToString(got); h != want {
+		return fmt.Errorf("%s mismatch: got %s, expected %s", what, h, want)
+	}
+	return nil
+}
+
+func katScalar(h string) curve.ECgFp5Scalar {
+	b, _ := hex.DecodeString(h)
+	return curve.ScalarElementFromLittleEndianBytes(b)
+}
+
+func checkPoseidon() error {
+	input := make([]g.Element, 8)
+	for i := range input {
+		input[i] = g.FromUint64(uint64(i))
+	}
+	return expectHex("hash", poseidon2.HashToQuinticExtension(input).ToLittleEndianBytes(), katPoseidonHash)
+}
+
+func checkPublicKey() error {
+	pk := schnorr.SchnorrPkFromSk(katScalar(katPrivateKey))
+	return expectHex("public key", pk.ToLittleEndianBytes(), katPublicKey)
+}
+
+func checkSchnorrSign() error {
+	msg := poseidon2.HashToQuinticExtension([]g.Element{g.FromUint64(1), g.FromUint64(2), g.FromUint64(3)})
+	if err := expectHex("message hash", msg.ToLittleEndianBytes(), katMessage); err != nil {
+		return err
+	}
+
+	sig := schnorr.SchnorrSignHashedMessage2(msg, katScalar(katPrivateKey), katScalar(katNonce))
+	if err := expectHex("signature", sig.ToBytes(), katSignature); err != nil {
+		return err
+	}
+
+	pubKey, _ := hex.DecodeString(katPublicKey)
+	if err := schnorr.Validate(pubKey, msg.ToLittleEndianBytes(), sig.ToBytes()); err != nil {
+		return fmt.Errorf("known signature rejected: %v", err)
+	}
+
+	// A signature must not verify for a different message
+	other := poseidon2.HashToQuinticExtension([]g.Element{g.FromUint64(1), g.FromUint64(2), g.FromUint64(4)})
+	if schnorr.Validate(pubKey, other.ToLittleEndianBytes(), sig.ToBytes()) == nil {
+		return fmt.Errorf("signature accepted for the wrong message")
+	}
+	return nil
+}
+
+// checkTx signs a tx through the same path as signTx, compares its hash
+// with the known answer and verifies the result the way verifyTx would
+func checkTx(txType uint8, params map[string]interface{}, wantHash string) error {
+	privateKey, _ := hex.DecodeString(katPrivateKey)
+	sess, err := signing.NewSigner(privateKey, signing.Account{ChainId: defaultChainId, AccountIndex: 1, ApiKeyIndex: 3})
+	if err != nil {
+		return err
+	}
+	pubKey, _ := hex.DecodeString(katPublicKey)
+	if pk := sess.PublicKey(); !bytes.Equal(pk, pubKey) {
+		return fmt.Errorf("signer public key mismatch: got %x", pk)
+	}
+
+	input := map[string]interface{}{
+		"nonce":     7,
+		"expiredAt": katTxTime.Add(signing.DefaultExpiry).UnixMilli(),
+	}
+	for k, v := range params {
+		input[k] = v
+	}
+
+	req, err := decodeTxRequest(txType, js.ValueOf(input))
+	if err != nil {
+		return err
+	}
+	result, err := sess.SignAt(req, katTxTime)
+	if err != nil {
+		return err
+	}
+	if result.TxHash != wantHash {
+		return fmt.Errorf("tx hash mismatch: got %s, expected %s", result.TxHash, wantHash)
+	}
+
+	tx, sig, err := signing.ParseTx(txType, result.TxInfo)
+	if err != nil {
+		return err
+	}
+	msgHash, err := signing.VerifyTx(tx, sig, defaultChainId, pubKey)
+	if msgHash == nil {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("signature rejected: %v", err)
+	}
+	if h := hex.EncodeToString(msgHash); h != result.TxHash {
+		return fmt.Errorf("tx hash mismatch: signed %s, recomputed %s", result.TxHash, h)
+	}
+
+	// Bump the nonce: the hash must change and the signature must not carry over
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(result.TxInfo), &fields); err != nil {
+		return err
+	}
+	fields["Nonce"] = 8
+	tampered, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	tx, sig, err = signing.ParseTx(txType, string(tampered))
+	if err != nil {
+		return err
+	}
+	otherHash, err := signing.VerifyTx(tx, sig, defaultChainId, pubKey)
+	if otherHash == nil {
+		return err
+	}
+	if bytes.Equal(otherHash, msgHash) {
+		return fmt.Errorf("tx hash does not cover the nonce")
+	}
+	if err == nil {
+		return fmt.Errorf("signature accepted for a different tx")
+	}
+	return nil
+}
+
+// selfTest reports the startup known-answer checks:
+// selfTest(). Resolves {passed, results: [{name, passed, error?}]}.
+func selfTest(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		results := make([]interface{}, len(selfTestResults))
+		for i, r := range selfTestResults {
+			entry := map[string]interface{}{
+				"name":   r.name,
+				"passed": r.err == nil,
+			}
+			if r.err != nil {
+				entry["error"] = r.err.Error()
+			}
+			results[i] = entry
+		}
+
+		return map[string]interface{}{
+			"passed":  selfTestPassed(selfTestResults),
+			"results": results,
+		}, nil
+	})
+}
@@ -0,0 +1,131 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"syscall/js"
+
+	"lighter-wasm/errcodes"
+	"lighter-wasm/signing"
+)
+
+// Sessions bind a signing.Signer to an opaque handle. They only live
+// inside Go, so the raw private key does not have to be passed around on
+// every call.
+var (
+	sessionsMu sync.Mutex
+	sessions   = make(map[string]*signing.Signer)
+)
+
+// decodePrivateKey parses a hex private key with or without 0x prefix
+func decodePrivateKey(privateKeyHex string) ([]byte, error) {
+	return signing.DecodeKey(privateKeyHex)
+}
+
+// newSession builds a signer from chainId, accountIndex, apiKeyIndex and
+// either privateKey or a keystore with its password
+func newSession(params js.Value) (*signing.Signer, error) {
+	var account signing.Account
+	if err := decodeParams(params, &account); err != nil {
+		return nil, err
+	}
+
+	privateKeyBytes, err := sessionKey(params)
+	if err != nil {
+		return nil, err
+	}
+	return signing.NewSigner(privateKeyBytes, account)
+}
+
+// sessionKey decodes the raw private key of a session. A keystore is
+// decrypted here so the plaintext key never exists on the JavaScript side.
+func sessionKey(params js.Value) ([]byte, error) {
+	if ks := params.Get("keystore"); !ks.IsUndefined() && !ks.IsNull() {
+		blob, err := keystoreArg(ks)
+		if err != nil {
+			return nil, err
+		}
+		password := params.Get("password")
+		if password.Type() != js.TypeString {
+			return nil, &paramError{Reason: reasonMissing, Field: "password", Message: "is required with keystore"}
+		}
+		return signing.DecryptKey([]byte(blob), []byte(password.String()))
+	}
+
+	privateKeyBytes, err := decodePrivateKey(params.Get("privateKey").String())
+	if err != nil {
+		return nil, signing.Errorf(errcodes.InvalidKey, "privateKey", err, "Invalid private key")
+	}
+	return privateKeyBytes, nil
+}
+
+// sessionFromParams resolves the signer for a sign call. Callers pass either
+// the handle returned by openSession or, as before, an inline privateKey
+// together with chainId, accountIndex and apiKeyIndex.
+func sessionFromParams(params js.Value) (*signing.Signer, error) {
+	handle := params.Get("session")
+	if handle.Type() != js.TypeString {
+		return newSession(params)
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	s, ok := sessions[handle.String()]
+	if !ok {
+		return nil, signing.Errorf(errcodes.InvalidKey, "session", nil, "Unknown or closed session")
+	}
+	return s, nil
+}
+
+// openSession creates a signing session and resolves its opaque handle
+func openSession(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("Missing arguments: no parameters provided")
+		}
+
+		s, err := newSession(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			s.Zeroize()
+			return nil, signing.Errorf(errcodes.Internal, "", err, "Failed to create session handle")
+		}
+		handle := "session-" + hex.EncodeToString(id[:])
+
+		sessionsMu.Lock()
+		sessions[handle] = s
+		sessionsMu.Unlock()
+
+		return handle, nil
+	})
+}
+
+// closeSession zeroizes and forgets a session. Resolves false if the handle
+// was unknown or already closed.
+func closeSession(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return nil, fmt.Errorf("Missing arguments: session handle required")
+		}
+
+		sessionsMu.Lock()
+		s, ok := sessions[args[0].String()]
+		if ok {
+			delete(sessions, args[0].String())
+			s.Zeroize()
+		}
+		sessionsMu.Unlock()
+
+		return ok, nil
+	})
+}
@@ -0,0 +1,88 @@
+package signing
+
+import (
+	"errors"
+	"fmt"
+
+	"lighter-wasm/errcodes"
+)
+
+// BatchParams are the parameters of a batch besides its txs. Entry i is
+// signed with nonce StartNonce+i; ExpiredAt applies to entries that do not
+// set their own.
+type BatchParams struct {
+	StartNonce int64 `param:"startNonce,min=0"`
+	ExpiredAt  int64 `param:"expiredAt,optional,min=0"`
+}
+
+// BatchEntries reads the {txType, params} entries of a batch from an
+// adapter's input. Errors name fields relative to the entry, and for
+// DecodeParams relative to its params.
+type BatchEntries interface {
+	Len() int
+	// TxType returns the tx type of entry i
+	TxType(i int) (uint8, error)
+	// DecodeParams fills req from the params of entry i, with nonce in
+	// place of any nonce they set, and runs Check
+	DecodeParams(i int, req TxRequest, nonce int64) error
+}
+
+// DecodeBatch decodes the entries of a batch starting at startNonce. The
+// first entry that fails is reported with its field under txs[i].
+func DecodeBatch(entries BatchEntries, startNonce int64) ([]TxRequest, error) {
+	n := entries.Len()
+	if n == 0 {
+		return nil, &ParamError{ReasonRange, "txs", "expected at least 1 items, got 0"}
+	}
+
+	reqs := make([]TxRequest, n)
+	for i := 0; i < n; i++ {
+		path := fmt.Sprintf("txs[%d]", i)
+		txType, err := entries.TxType(i)
+		if err != nil {
+			return nil, qualify(path, err)
+		}
+		kind, ok := kinds[txType]
+		if !ok {
+			return nil, Errorf(errcodes.InvalidParam, path+".txType", nil, "Unsupported tx type: %d", txType)
+		}
+
+		req := kind.NewRequest()
+		if err := entries.DecodeParams(i, req, startNonce+int64(i)); err != nil {
+			return nil, qualify(path+".params", err)
+		}
+		reqs[i] = req
+	}
+	return reqs, nil
+}
+
+// QualifyBatchError names the entry a *BatchError from SignBatch refers to
+// in the error's field, as DecodeBatch does; other errors are returned as
+// they are
+func QualifyBatchError(err error) error {
+	var berr *BatchError
+	if !errors.As(err, &berr) {
+		return err
+	}
+	return qualify(fmt.Sprintf("txs[%d].params", berr.Index), berr.Err)
+}
+
+// qualify moves the field of err under path
+func qualify(path string, err error) error {
+	var perr *ParamError
+	if errors.As(err, &perr) {
+		return &ParamError{perr.Reason, joinField(path, perr.Field), perr.Message}
+	}
+	var serr *Error
+	if errors.As(err, &serr) && serr.Field != "" {
+		return Errorf(serr.Code, joinField(path, serr.Field), err, "%s", path)
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+func joinField(path, field string) string {
+	if field == "" {
+		return path
+	}
+	return path + "." + field
+}
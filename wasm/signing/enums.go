@@ -0,0 +1,56 @@
+package signing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Enum is a dense set of named values starting at 0, mirroring the tables
+// in sdk/core/constants.js
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+// The enums request fields are checked against
+var (
+	OrderTypes    = Enum{"ORDER_TYPES", []string{"LIMIT", "MARKET", "STOP_LOSS", "TAKE_PROFIT", "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT", "TWAP"}}
+	TimeInForces  = Enum{"TIME_IN_FORCE", []string{"IMMEDIATE_OR_CANCEL", "GOOD_TILL_TIME", "POST_ONLY"}}
+	GroupingTypes = Enum{"GROUPING_TYPES", []string{"NONE", "ONE_TRIGGERS_OTHER", "ONE_CANCELS_OTHER", "ONE_TRIGGERS_OCO"}}
+	CancelAllTIFs = Enum{"CANCEL_ALL_TIF", []string{"IMMEDIATE", "SCHEDULED", "ABORT"}}
+	OrderSides    = Enum{"ORDER_SIDES", []string{"BUY", "SELL"}}
+	MarginModes   = Enum{"MARGIN_MODES", []string{"CROSS", "ISOLATED"}}
+	MarginDirs    = Enum{"MARGIN_DIRECTION", []string{"REMOVE", "ADD"}}
+	PoolStatuses  = Enum{"POOL_STATUS", []string{"INACTIVE", "ACTIVE"}}
+	BooleanFlags  = Enum{"flag", []string{"false", "true"}}
+)
+
+// enums resolves the enum= option of param tags
+var enums = map[string]*Enum{
+	OrderTypes.Name:    &OrderTypes,
+	TimeInForces.Name:  &TimeInForces,
+	GroupingTypes.Name: &GroupingTypes,
+	CancelAllTIFs.Name: &CancelAllTIFs,
+	OrderSides.Name:    &OrderSides,
+	MarginModes.Name:   &MarginModes,
+	MarginDirs.Name:    &MarginDirs,
+	PoolStatuses.Name:  &PoolStatuses,
+	BooleanFlags.Name:  &BooleanFlags,
+}
+
+// Describe lists the values, e.g. "ORDER_SIDES (BUY=0, SELL=1)"
+func (e *Enum) Describe() string {
+	names := make([]string, len(e.Values))
+	for i, name := range e.Values {
+		names[i] = fmt.Sprintf("%s=%d", name, i)
+	}
+	return fmt.Sprintf("%s (%s)", e.Name, strings.Join(names, ", "))
+}
+
+// Lookup returns the name of value, or false if it is out of range
+func (e *Enum) Lookup(value int64) (string, bool) {
+	if value < 0 || value >= int64(len(e.Values)) {
+		return "", false
+	}
+	return e.Values[value], true
+}
@@ -0,0 +1,64 @@
+package signing
+
+import "fmt"
+
+// ParamError reports a request field that fails its constraints. Field is
+// the parameter name as the JS SDK spells it, e.g. "orders[1].price".
+type ParamError struct {
+	Reason  string
+	Field   string
+	Message string
+}
+
+// Reasons a parameter is rejected
+const (
+	ReasonMissing = "MISSING"
+	ReasonType    = "TYPE"
+	ReasonRange   = "RANGE"
+	ReasonEnum    = "ENUM"
+	ReasonExpired = "EXPIRED"
+)
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("Invalid parameter %s: %s", e.Field, e.Message)
+}
+
+// Error is a failure with a stable errcodes code. Cause is the underlying
+// error, if any, and is appended to the message.
+type Error struct {
+	Code    string
+	Field   string
+	Message string
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Errorf builds an Error for field (empty if no single field is at fault)
+// with the formatted message and optional cause
+func Errorf(code, field string, cause error, format string, args ...interface{}) error {
+	return &Error{code, field, fmt.Sprintf(format, args...), cause}
+}
+
+// BatchError reports the entry of a batch that failed to sign
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("tx %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,34 @@
+package signing
+
+import (
+	"fmt"
+	"time"
+)
+
+// Expiry policy for TxOpts.ExpiredAt. A tx signed without one expires
+// DefaultExpiry after signing; an explicit ExpiredAt (Unix milliseconds)
+// must fall within [MinExpiryWindow, MaxExpiryWindow] from now.
+const (
+	DefaultExpiry   = 10 * time.Minute
+	MinExpiryWindow = 1 * time.Minute
+	MaxExpiryWindow = 28 * 24 * time.Hour
+)
+
+// ResolveExpiredAt applies the expiry policy, treating 0 as "use the
+// default".
+func ResolveExpiredAt(expiredAt int64, now time.Time) (int64, error) {
+	if expiredAt == 0 {
+		return now.Add(DefaultExpiry).UnixMilli(), nil
+	}
+
+	window := time.Duration(expiredAt-now.UnixMilli()) * time.Millisecond
+	switch {
+	case window <= 0:
+		return 0, &ParamError{ReasonExpired, "expiredAt", fmt.Sprintf("%d is already in the past (now %d)", expiredAt, now.UnixMilli())}
+	case window < MinExpiryWindow:
+		return 0, &ParamError{ReasonRange, "expiredAt", fmt.Sprintf("must be at least %v from now, got %v", MinExpiryWindow, window.Round(time.Second))}
+	case window > MaxExpiryWindow:
+		return 0, &ParamError{ReasonRange, "expiredAt", fmt.Sprintf("must be at most %v from now, got %v", MaxExpiryWindow, window.Round(time.Second))}
+	}
+	return expiredAt, nil
+}
@@ -0,0 +1,165 @@
+package signing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"lighter-wasm/errcodes"
+)
+
+// DecodeParams fills req, a pointer to a request struct, from a JSON object
+// that uses the same parameter names as the JS SDK, then runs Check.
+// Integers may be JSON numbers or base-10 strings, so values beyond 2^53
+// survive JavaScript producers; byte fields are hex strings.
+func DecodeParams(data []byte, req interface{}) error {
+	if perr := decodeJSONStruct(data, reflect.ValueOf(req).Elem(), ""); perr != nil {
+		return perr
+	}
+	return Check(req)
+}
+
+// NewTxRequest decodes JSON params into a new request for txType
+func NewTxRequest(txType uint8, params []byte) (TxRequest, error) {
+	kind, ok := kinds[txType]
+	if !ok {
+		return nil, Errorf(errcodes.InvalidParam, "txType", nil, "Unsupported tx type: %d", txType)
+	}
+
+	req := kind.NewRequest()
+	if err := DecodeParams(params, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// SignedTx is the JSON form of a Result, with the shape of the object
+// LighterWASM sign* functions resolve
+type SignedTx struct {
+	TxType       uint8  `json:"txType"`
+	TxInfo       string `json:"txInfo"`
+	TxHash       string `json:"txHash,omitempty"`
+	Nonce        int64  `json:"nonce"`
+	ExpiredAt    int64  `json:"expiredAt"`
+	AccountIndex int64  `json:"accountIndex"`
+	ApiKeyIndex  uint8  `json:"apiKeyIndex"`
+	L1Message    string `json:"l1Message,omitempty"`
+}
+
+// NewSignedTx returns the JSON form of r
+func NewSignedTx(r *Result) *SignedTx {
+	return &SignedTx{
+		TxType:       r.TxType,
+		TxInfo:       r.TxInfo,
+		TxHash:       r.TxHash,
+		Nonce:        r.Nonce,
+		ExpiredAt:    r.ExpiredAt,
+		AccountIndex: r.AccountIndex,
+		ApiKeyIndex:  r.ApiKeyIndex,
+		L1Message:    r.L1Message,
+	}
+}
+
+// ParseTxType accepts a TX_TYPES name such as "CREATE_ORDER" or a number
+func ParseTxType(s string) (uint8, error) {
+	if txType, ok := TxTypeByName(strings.ToUpper(s)); ok {
+		return txType, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, Errorf(errcodes.InvalidParam, "txType", nil, "Invalid tx type: %s", s)
+	}
+	if _, ok := kinds[uint8(n)]; !ok {
+		return 0, Errorf(errcodes.InvalidParam, "txType", nil, "Unsupported tx type: %d", n)
+	}
+	return uint8(n), nil
+}
+
+func decodeJSONStruct(data []byte, v reflect.Value, prefix string) *ParamError {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return &ParamError{ReasonType, strings.TrimSuffix(prefix, "."), "expected an object"}
+	}
+
+	for _, f := range Fields(v.Type()) {
+		if perr := decodeJSONField(obj[f.Name], &f, v.FieldByIndex(f.Index), prefix+f.Name); perr != nil {
+			return perr
+		}
+	}
+	return nil
+}
+
+func decodeJSONField(raw json.RawMessage, f *Field, v reflect.Value, path string) *ParamError {
+	if len(raw) == 0 || string(raw) == "null" {
+		if !f.Optional {
+			return &ParamError{ReasonMissing, path, "is required"}
+		}
+		return nil
+	}
+
+	switch {
+	case f.IsInteger():
+		n, err := jsonInteger(raw)
+		if err != nil {
+			return &ParamError{ReasonType, path, err.Error()}
+		}
+		if perr := f.CheckRange(n, path); perr != nil {
+			return perr
+		}
+		SetInt(v, n)
+
+	case v.Kind() == reflect.String:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return &ParamError{ReasonType, path, "expected a string"}
+		}
+		v.SetString(s)
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return &ParamError{ReasonType, path, "expected a hex string"}
+		}
+		b, err := DecodeKey(s)
+		if err != nil {
+			return &ParamError{ReasonType, path, fmt.Sprintf("invalid hex: %v", err)}
+		}
+		v.SetBytes(b)
+
+	case v.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return &ParamError{ReasonType, path, "expected an array"}
+		}
+		out := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if perr := decodeJSONStruct(item, out.Index(i), fmt.Sprintf("%s[%d].", path, i)); perr != nil {
+				return perr
+			}
+		}
+		v.Set(out)
+	}
+	return nil
+}
+
+// jsonInteger parses a JSON number or base-10 string without going through
+// float64
+func jsonInteger(raw json.RawMessage) (*big.Int, error) {
+	text := string(bytes.TrimSpace(raw))
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, fmt.Errorf("expected number or decimal string")
+		}
+		text = strings.TrimSpace(text)
+	}
+
+	n, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a base-10 integer", text)
+	}
+	return n, nil
+}
@@ -0,0 +1,75 @@
+package signing
+
+import (
+	"hash"
+	"sync"
+
+	"github.com/elliottech/lighter-go/signer"
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"lighter-wasm/errcodes"
+)
+
+// keyManager implements lighter-go's signer.KeyManager over a scalar the
+// Signer owns. lighter-go's own key manager keeps a private copy of the
+// scalar that nothing can overwrite; this one is wiped by zeroize.
+type keyManager struct {
+	mu     sync.RWMutex
+	key    curve.ECgFp5Scalar
+	pubKey gFp5.Element
+	wiped  bool
+}
+
+var _ signer.KeyManager = (*keyManager)(nil)
+
+func newKeyManager(privateKey []byte) (*keyManager, error) {
+	if _, err := PublicKey(privateKey); err != nil {
+		return nil, err
+	}
+	key := curve.ScalarElementFromLittleEndianBytes(privateKey)
+	return &keyManager{key: key, pubKey: schnorr.SchnorrPkFromSk(key)}, nil
+}
+
+// Sign signs a 40-byte Poseidon2 message hash; hFunc is unused, as with
+// lighter-go's key manager
+func (k *keyManager) Sign(hashedMessage []byte, hFunc hash.Hash) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.wiped {
+		return nil, Errorf(errcodes.InvalidKey, "", nil, "Key has been zeroized")
+	}
+
+	msg, err := gFp5.FromCanonicalLittleEndianBytes(hashedMessage)
+	if err != nil {
+		return nil, err
+	}
+	return schnorr.SchnorrSignHashedMessage(msg, k.key).ToBytes(), nil
+}
+
+func (k *keyManager) PubKey() gFp5.Element {
+	return k.pubKey
+}
+
+func (k *keyManager) PubKeyBytes() (pk [KeySize]byte) {
+	copy(pk[:], k.pubKey.ToLittleEndianBytes())
+	return pk
+}
+
+// PrvKeyBytes returns a copy of the scalar, or nil once wiped
+func (k *keyManager) PrvKeyBytes() []byte {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.wiped {
+		return nil
+	}
+	return k.key.ToLittleEndianBytes()
+}
+
+// zeroize overwrites the scalar once signatures in flight are done
+func (k *keyManager) zeroize() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = curve.ECgFp5Scalar{}
+	k.wiped = true
+}
@@ -0,0 +1,35 @@
+package signing
+
+import (
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"lighter-wasm/errcodes"
+)
+
+// KeySize is the length of an encoded ecgfp5 scalar or public key
+const KeySize = 40
+
+// PublicKey derives the public key of an API private key. The key must be
+// a canonical little-endian scalar, i.e. below the group order, and not
+// zero.
+func PublicKey(privateKey []byte) ([]byte, error) {
+	if len(privateKey) != KeySize {
+		return nil, Errorf(errcodes.InvalidKey, "privateKey", nil, "Invalid private key: expected %d bytes, got %d", KeySize, len(privateKey))
+	}
+
+	key := curve.ScalarElementFromLittleEndianBytes(privateKey)
+	if curve.BigIntFromArray(key).Cmp(curve.ORDER) >= 0 {
+		return nil, Errorf(errcodes.InvalidKey, "privateKey", nil, "Invalid private key: not a canonical scalar")
+	}
+	if key.IsZero() {
+		return nil, Errorf(errcodes.InvalidKey, "privateKey", nil, "Invalid private key: zero scalar")
+	}
+
+	return schnorr.SchnorrPkFromSk(key).ToLittleEndianBytes(), nil
+}
+
+// GenerateKey samples a new API key pair
+func GenerateKey() (privateKey, publicKey []byte) {
+	key := curve.SampleScalar(nil)
+	return key.ToLittleEndianBytes(), schnorr.SchnorrPkFromSk(key).ToLittleEndianBytes()
+}
@@ -0,0 +1,193 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+	"lighter-wasm/errcodes"
+)
+
+// Keystore format version 1: the 40-byte API private key encrypted with
+// AES-256-GCM under a scrypt-derived key, laid out like an Ethereum
+// keystore. The public key is stored in the clear so a keystore can be
+// matched to an API key index without the password, and is bound to the
+// ciphertext as GCM additional data.
+const (
+	keystoreVersion = 1
+	keystoreKind    = "lighter-api-key"
+	keystoreCipher  = "aes-256-gcm"
+	keystoreKDF     = "scrypt"
+
+	// scrypt cost defaults. N is kept below the Ethereum "standard" 2^18
+	// because the 128*N*r bytes of scratch memory come out of the WASM
+	// heap; MaxScryptN bounds what a keystore file can demand (128 MiB).
+	DefaultScryptN = 1 << 15
+	MinScryptN     = 1 << 10
+	MaxScryptN     = 1 << 17
+	scryptR        = 8
+	scryptP        = 1
+	scryptKeyLen   = 32
+	scryptSaltLen  = 32
+)
+
+// Keystore is an encrypted API private key, stored as JSON
+type Keystore struct {
+	Version   int            `json:"version"`
+	Kind      string         `json:"kind"`
+	PublicKey string         `json:"publicKey"`
+	Crypto    KeystoreCrypto `json:"crypto"`
+}
+
+type KeystoreCrypto struct {
+	Cipher     string       `json:"cipher"`
+	CipherText string       `json:"ciphertext"`
+	Nonce      string       `json:"nonce"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdfparams"`
+}
+
+type ScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// DecodeKey parses a hex key with or without 0x prefix
+func DecodeKey(keyHex string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(keyHex), "0x"))
+}
+
+// aead derives the AES-GCM cipher for a password and scrypt parameters
+func (p ScryptParams) aead(password []byte) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %v", err)
+	}
+
+	derived, err := scrypt.Key(password, salt, p.N, p.R, p.P, p.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range derived {
+			derived[i] = 0
+		}
+	}()
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptKey seals a private key into a version 1 keystore. n is the
+// scrypt cost, a power of two between MinScryptN and MaxScryptN.
+func EncryptKey(privateKey, password []byte, n int) (*Keystore, error) {
+	if len(password) == 0 {
+		return nil, &ParamError{ReasonMissing, "password", "must not be empty"}
+	}
+	if n < MinScryptN || n > MaxScryptN {
+		return nil, &ParamError{ReasonRange, "scryptN", fmt.Sprintf("expected %d to %d, got %d", MinScryptN, MaxScryptN, n)}
+	}
+	if n&(n-1) != 0 {
+		return nil, &ParamError{ReasonRange, "scryptN", fmt.Sprintf("%d is not a power of two", n)}
+	}
+	publicKey, err := PublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, Errorf(errcodes.Internal, "", err, "Failed to encrypt key")
+	}
+
+	params := ScryptParams{N: n, R: scryptR, P: scryptP, DKLen: scryptKeyLen, Salt: hex.EncodeToString(salt)}
+	gcm, err := params.aead(password)
+	if err != nil {
+		return nil, Errorf(errcodes.Internal, "", err, "Failed to encrypt key")
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, Errorf(errcodes.Internal, "", err, "Failed to encrypt key")
+	}
+
+	return &Keystore{
+		Version:   keystoreVersion,
+		Kind:      keystoreKind,
+		PublicKey: "0x" + hex.EncodeToString(publicKey),
+		Crypto: KeystoreCrypto{
+			Cipher:     keystoreCipher,
+			CipherText: hex.EncodeToString(gcm.Seal(nil, nonce, privateKey, publicKey)),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        keystoreKDF,
+			KDFParams:  params,
+		},
+	}, nil
+}
+
+// DecryptKey opens a JSON keystore and checks the key against the stored
+// public key. The caller owns, and should zeroize, the returned bytes.
+func DecryptKey(blob, password []byte) ([]byte, error) {
+	var ks Keystore
+	if err := json.Unmarshal(blob, &ks); err != nil {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", err, "Invalid keystore")
+	}
+	if ks.Version != keystoreVersion || ks.Kind != keystoreKind {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", nil, "Unsupported keystore: version %d kind %q", ks.Version, ks.Kind)
+	}
+	if ks.Crypto.Cipher != keystoreCipher || ks.Crypto.KDF != keystoreKDF {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", nil, "Unsupported keystore: cipher %q kdf %q", ks.Crypto.Cipher, ks.Crypto.KDF)
+	}
+
+	p := ks.Crypto.KDFParams
+	if p.N < MinScryptN || p.N > MaxScryptN || p.R != scryptR || p.P != scryptP || p.DKLen != scryptKeyLen {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", nil, "Unsupported keystore: scrypt parameters out of range")
+	}
+
+	publicKey, err := DecodeKey(ks.PublicKey)
+	if err != nil || len(publicKey) != KeySize {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", err, "Invalid keystore: bad public key")
+	}
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", err, "Invalid keystore: bad nonce")
+	}
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", err, "Invalid keystore: bad ciphertext")
+	}
+
+	gcm, err := p.aead(password)
+	if err != nil {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", err, "Invalid keystore")
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, Errorf(errcodes.InvalidParam, "keystore", nil, "Invalid keystore: bad nonce length")
+	}
+
+	privateKey, err := gcm.Open(nil, nonce, cipherText, publicKey)
+	if err != nil {
+		return nil, Errorf(errcodes.InvalidKey, "password", nil, "Failed to decrypt key: wrong password or corrupted keystore")
+	}
+
+	derived, err := PublicKey(privateKey)
+	if err != nil || !bytes.Equal(derived, publicKey) {
+		for i := range privateKey {
+			privateKey[i] = 0
+		}
+		return nil, Errorf(errcodes.InvalidKey, "keystore", nil, "Failed to decrypt key: key does not match keystore public key")
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,123 @@
+package signing
+
+import (
+	"sort"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// Kind describes one L2 tx type
+type Kind struct {
+	// Name is the TX_TYPES key in sdk/core/constants.js
+	Name string
+	// Action completes "Failed to sign ..." in errors
+	Action string
+	// NewTx returns an empty lighter-go tx info to decode tx_info into
+	NewTx func() txtypes.TxInfo
+	// NewRequest returns an empty request to fill in
+	NewRequest func() TxRequest
+}
+
+var kinds = map[uint8]Kind{
+	txtypes.TxTypeL2ChangePubKey: {
+		"CHANGE_PUB_KEY", "pub key change",
+		func() txtypes.TxInfo { return &txtypes.L2ChangePubKeyTxInfo{} },
+		func() TxRequest { return &ChangePubKey{} },
+	},
+	txtypes.TxTypeL2CreateSubAccount: {
+		"CREATE_SUB_ACCOUNT", "sub-account creation",
+		func() txtypes.TxInfo { return &txtypes.L2CreateSubAccountTxInfo{} },
+		func() TxRequest { return &CreateSubAccount{} },
+	},
+	txtypes.TxTypeL2CreatePublicPool: {
+		"CREATE_PUBLIC_POOL", "pool creation",
+		func() txtypes.TxInfo { return &txtypes.L2CreatePublicPoolTxInfo{} },
+		func() TxRequest { return &CreatePublicPool{} },
+	},
+	txtypes.TxTypeL2UpdatePublicPool: {
+		"UPDATE_PUBLIC_POOL", "pool update",
+		func() txtypes.TxInfo { return &txtypes.L2UpdatePublicPoolTxInfo{} },
+		func() TxRequest { return &UpdatePublicPool{} },
+	},
+	txtypes.TxTypeL2Transfer: {
+		"TRANSFER", "transfer",
+		func() txtypes.TxInfo { return &txtypes.L2TransferTxInfo{} },
+		func() TxRequest { return &Transfer{} },
+	},
+	txtypes.TxTypeL2Withdraw: {
+		"WITHDRAW", "withdrawal",
+		func() txtypes.TxInfo { return &txtypes.L2WithdrawTxInfo{} },
+		func() TxRequest { return &Withdraw{} },
+	},
+	txtypes.TxTypeL2CreateOrder: {
+		"CREATE_ORDER", "order",
+		func() txtypes.TxInfo { return &txtypes.L2CreateOrderTxInfo{} },
+		func() TxRequest { return &CreateOrder{} },
+	},
+	txtypes.TxTypeL2CancelOrder: {
+		"CANCEL_ORDER", "cancel",
+		func() txtypes.TxInfo { return &txtypes.L2CancelOrderTxInfo{} },
+		func() TxRequest { return &CancelOrder{} },
+	},
+	txtypes.TxTypeL2CancelAllOrders: {
+		"CANCEL_ALL_ORDERS", "cancel all",
+		func() txtypes.TxInfo { return &txtypes.L2CancelAllOrdersTxInfo{} },
+		func() TxRequest { return &CancelAllOrders{} },
+	},
+	txtypes.TxTypeL2ModifyOrder: {
+		"MODIFY_ORDER", "modify",
+		func() txtypes.TxInfo { return &txtypes.L2ModifyOrderTxInfo{} },
+		func() TxRequest { return &ModifyOrder{} },
+	},
+	txtypes.TxTypeL2MintShares: {
+		"MINT_SHARES", "mint shares",
+		func() txtypes.TxInfo { return &txtypes.L2MintSharesTxInfo{} },
+		func() TxRequest { return &MintShares{} },
+	},
+	txtypes.TxTypeL2BurnShares: {
+		"BURN_SHARES", "burn shares",
+		func() txtypes.TxInfo { return &txtypes.L2BurnSharesTxInfo{} },
+		func() TxRequest { return &BurnShares{} },
+	},
+	txtypes.TxTypeL2UpdateLeverage: {
+		"UPDATE_LEVERAGE", "leverage update",
+		func() txtypes.TxInfo { return &txtypes.L2UpdateLeverageTxInfo{} },
+		func() TxRequest { return &UpdateLeverage{} },
+	},
+	txtypes.TxTypeL2CreateGroupedOrders: {
+		"CREATE_GROUPED_ORDERS", "grouped orders",
+		func() txtypes.TxInfo { return &txtypes.L2CreateGroupedOrdersTxInfo{} },
+		func() TxRequest { return &CreateGroupedOrders{} },
+	},
+	txtypes.TxTypeL2UpdateMargin: {
+		"UPDATE_MARGIN", "margin update",
+		func() txtypes.TxInfo { return &txtypes.L2UpdateMarginTxInfo{} },
+		func() TxRequest { return &UpdateMargin{} },
+	},
+}
+
+// KindOf returns the description of a supported tx type
+func KindOf(txType uint8) (Kind, bool) {
+	kind, ok := kinds[txType]
+	return kind, ok
+}
+
+// TxTypeByName resolves a TX_TYPES name such as "CREATE_ORDER"
+func TxTypeByName(name string) (uint8, bool) {
+	for txType, kind := range kinds {
+		if kind.Name == name {
+			return txType, true
+		}
+	}
+	return 0, false
+}
+
+// TxTypes lists the supported tx types in ascending order
+func TxTypes() []uint8 {
+	txTypes := make([]uint8, 0, len(kinds))
+	for txType := range kinds {
+		txTypes = append(txTypes, txType)
+	}
+	sort.Slice(txTypes, func(i, j int) bool { return txTypes[i] < txTypes[j] })
+	return txTypes
+}
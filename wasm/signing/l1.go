@@ -0,0 +1,81 @@
+package signing
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"lighter-wasm/errcodes"
+)
+
+// L1SignedTx is implemented by txs that also need the account owner's
+// Ethereum signature, i.e. ChangePubKey
+type L1SignedTx interface {
+	GetL1SignatureBody() string
+}
+
+// SignL1Message personal_signs message with an Ethereum private key and
+// returns the 65-byte signature with v in {27, 28}
+func SignL1Message(message string, privateKey []byte) ([]byte, error) {
+	key, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, Errorf(errcodes.InvalidKey, "l1PrivateKey", err, "Invalid L1 private key")
+	}
+
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		return nil, Errorf(errcodes.SignFailed, "", err, "Failed to sign L1 message")
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// RecoverL1Address returns the Ethereum address that personal_signed message
+func RecoverL1Address(message string, sig []byte) (common.Address, error) {
+	if len(sig) != 65 {
+		return common.Address{}, fmt.Errorf("expected 65 bytes, got %d", len(sig))
+	}
+
+	rsv := make([]byte, 65)
+	copy(rsv, sig)
+	if rsv[64] >= 27 {
+		rsv[64] -= 27
+	}
+
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), rsv)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// AttachL1 fills L1Sig from an existing signature or by signing with an
+// Ethereum private key, and returns the signer's address. With neither the
+// tx is left for the caller to sign with its wallet.
+func AttachL1(tx *txtypes.L2ChangePubKeyTxInfo, sig, privateKey []byte) (common.Address, error) {
+	if len(sig) > 0 && len(privateKey) > 0 {
+		return common.Address{}, Errorf(errcodes.InvalidParam, "l1Signature", nil, "Pass either l1Signature or l1PrivateKey, not both")
+	}
+	if len(sig) == 0 && len(privateKey) == 0 {
+		return common.Address{}, nil
+	}
+
+	message := tx.GetL1SignatureBody()
+	if len(privateKey) > 0 {
+		var err error
+		if sig, err = SignL1Message(message, privateKey); err != nil {
+			return common.Address{}, err
+		}
+	}
+
+	address, err := RecoverL1Address(message, sig)
+	if err != nil {
+		return common.Address{}, Errorf(errcodes.InvalidParam, "l1Signature", err, "Invalid L1 signature")
+	}
+
+	tx.L1Sig = "0x" + hex.EncodeToString(sig)
+	return address, nil
+}
@@ -0,0 +1,211 @@
+package signing
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Request structs declare their parameters with a param tag:
+//
+//	param:"name[,optional][,default=N][,min=N][,max=N][,enum=NAME][,size=N][,minItems=N]"
+//
+// name is the JS parameter name. optional only matters to callers that can
+// tell a missing value from zero, like the WASM adapter; Check treats a
+// zero value with a default as unset. size is the exact length of a []byte
+// field and the maximum length of a string. Embedded structs contribute
+// their fields in place.
+
+// Field is one tagged request field
+type Field struct {
+	Name     string
+	Index    []int
+	Type     reflect.Type
+	Optional bool
+	Default  *big.Int
+	Min, Max *big.Int // effective bounds: the tag's, else the Go type's
+	Enum     *Enum
+	Size     int
+	MinItems int
+}
+
+var (
+	zero      = big.NewInt(0)
+	typeRange = map[reflect.Kind][2]*big.Int{
+		reflect.Int64:  {big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+		reflect.Uint8:  {zero, big.NewInt(math.MaxUint8)},
+		reflect.Uint16: {zero, big.NewInt(math.MaxUint16)},
+		reflect.Uint32: {zero, big.NewInt(math.MaxUint32)},
+		reflect.Uint64: {zero, new(big.Int).SetUint64(math.MaxUint64)},
+	}
+	fieldCache sync.Map // reflect.Type -> []Field
+)
+
+// IsInteger reports whether Field holds an integer kind
+func (f *Field) IsInteger() bool {
+	_, ok := typeRange[f.Type.Kind()]
+	return ok
+}
+
+// Fields returns the tagged fields of a request struct type in declaration
+// order. It panics on a malformed tag, which is a bug in this package.
+func Fields(t reflect.Type) []Field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]Field)
+	}
+	fields := collectFields(t, nil)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(sf.Type, idx)...)
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("param")
+		if !ok {
+			continue
+		}
+		fields = append(fields, parseTag(sf, idx, tag))
+	}
+	return fields
+}
+
+func parseTag(sf reflect.StructField, index []int, tag string) Field {
+	parts := strings.Split(tag, ",")
+	f := Field{Name: parts[0], Index: index, Type: sf.Type}
+	if r, ok := typeRange[sf.Type.Kind()]; ok {
+		f.Min, f.Max = r[0], r[1]
+	}
+
+	for _, opt := range parts[1:] {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "optional":
+			f.Optional = true
+		case "default":
+			f.Default = mustInt(sf, value)
+		case "min":
+			f.Min = mustInt(sf, value)
+		case "max":
+			f.Max = mustInt(sf, value)
+		case "enum":
+			e, ok := enums[value]
+			if !ok {
+				panic(fmt.Sprintf("signing: %s: unknown enum %q", sf.Name, value))
+			}
+			f.Enum = e
+		case "size":
+			f.Size = int(mustInt(sf, value).Int64())
+		case "minItems":
+			f.MinItems = int(mustInt(sf, value).Int64())
+		default:
+			panic(fmt.Sprintf("signing: %s: unknown param option %q", sf.Name, key))
+		}
+	}
+	return f
+}
+
+func mustInt(sf reflect.StructField, s string) *big.Int {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("signing: %s: bad tag value %q", sf.Name, s))
+	}
+	return big.NewInt(n)
+}
+
+// Check applies defaults to req, a pointer to a request struct, and
+// validates every field against its tag. It returns the first field that
+// does not conform as a *ParamError.
+func Check(req interface{}) error {
+	if perr := checkStruct(reflect.ValueOf(req).Elem(), ""); perr != nil {
+		return perr
+	}
+	return nil
+}
+
+func checkStruct(v reflect.Value, prefix string) *ParamError {
+	for _, f := range Fields(v.Type()) {
+		if perr := f.check(v.FieldByIndex(f.Index), prefix+f.Name); perr != nil {
+			return perr
+		}
+	}
+	return nil
+}
+
+// IntValue reads an integer field as a big.Int
+func IntValue(v reflect.Value) *big.Int {
+	if v.Kind() == reflect.Int64 {
+		return big.NewInt(v.Int())
+	}
+	return new(big.Int).SetUint64(v.Uint())
+}
+
+// SetInt stores n, already range checked, in an integer field
+func SetInt(v reflect.Value, n *big.Int) {
+	if v.Kind() == reflect.Int64 {
+		v.SetInt(n.Int64())
+		return
+	}
+	v.SetUint(n.Uint64())
+}
+
+func (f *Field) check(v reflect.Value, path string) *ParamError {
+	switch {
+	case f.IsInteger():
+		n := IntValue(v)
+		if n.Sign() == 0 && f.Default != nil {
+			n = f.Default
+			SetInt(v, n)
+		}
+		if perr := f.CheckRange(n, path); perr != nil {
+			return perr
+		}
+		if f.Enum != nil {
+			if _, ok := f.Enum.Lookup(n.Int64()); !ok {
+				return &ParamError{ReasonEnum, path, fmt.Sprintf("%s is not a valid %s", n, f.Enum.Describe())}
+			}
+		}
+
+	case v.Kind() == reflect.String:
+		if f.Size > 0 && v.Len() > f.Size {
+			return &ParamError{ReasonRange, path, fmt.Sprintf("longer than %d bytes", f.Size)}
+		}
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		if f.Optional && v.Len() == 0 {
+			return nil
+		}
+		if f.Size > 0 && v.Len() != f.Size {
+			return &ParamError{ReasonRange, path, fmt.Sprintf("expected %d bytes, got %d", f.Size, v.Len())}
+		}
+
+	case v.Kind() == reflect.Slice:
+		if v.Len() < f.MinItems {
+			return &ParamError{ReasonRange, path, fmt.Sprintf("expected at least %d items, got %d", f.MinItems, v.Len())}
+		}
+		for i := 0; i < v.Len(); i++ {
+			if perr := checkStruct(v.Index(i), fmt.Sprintf("%s[%d].", path, i)); perr != nil {
+				return perr
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRange checks n against the field's effective bounds
+func (f *Field) CheckRange(n *big.Int, path string) *ParamError {
+	if n.Cmp(f.Min) < 0 || n.Cmp(f.Max) > 0 {
+		return &ParamError{ReasonRange, path, fmt.Sprintf("%s out of range [%s, %s]", n, f.Min, f.Max)}
+	}
+	return nil
+}
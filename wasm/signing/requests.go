@@ -0,0 +1,351 @@
+package signing
+
+import (
+	"github.com/elliottech/lighter-go/signer"
+	"github.com/elliottech/lighter-go/types"
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// TxRequest is implemented by the request struct of every L2 tx type
+type TxRequest interface {
+	TxType() uint8
+	opts() *TxOpts
+	build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error)
+}
+
+// Tx is implemented by every tx info returned from types.Construct*
+type Tx interface {
+	GetTxType() uint8
+	GetTxHash() string
+}
+
+// TxOpts are the fields every L2 tx carries
+type TxOpts struct {
+	Nonce int64 `param:"nonce,min=0"`
+	// ExpiredAt is Unix milliseconds; 0 means DefaultExpiry after signing
+	ExpiredAt int64 `param:"expiredAt,optional,min=0"`
+}
+
+func (o *TxOpts) opts() *TxOpts {
+	return o
+}
+
+// Order is one order of CreateOrder or CreateGroupedOrders
+type Order struct {
+	MarketIndex      uint8  `param:"marketIndex"`
+	ClientOrderIndex int64  `param:"clientOrderIndex,min=0"`
+	BaseAmount       int64  `param:"baseAmount,min=0"`
+	Price            uint32 `param:"price"`
+	IsAsk            uint8  `param:"isAsk,enum=ORDER_SIDES"`
+	OrderType        uint8  `param:"orderType,enum=ORDER_TYPES"`
+	TimeInForce      uint8  `param:"timeInForce,enum=TIME_IN_FORCE"`
+	ReduceOnly       uint8  `param:"reduceOnly,optional,enum=flag"`
+	TriggerPrice     uint32 `param:"triggerPrice,optional"`
+	OrderExpiry      int64  `param:"orderExpiry,optional,min=-1"`
+}
+
+func (o *Order) request() *types.CreateOrderTxReq {
+	return &types.CreateOrderTxReq{
+		MarketIndex:      o.MarketIndex,
+		ClientOrderIndex: o.ClientOrderIndex,
+		BaseAmount:       o.BaseAmount,
+		Price:            o.Price,
+		IsAsk:            o.IsAsk,
+		Type:             o.OrderType,
+		TimeInForce:      o.TimeInForce,
+		ReduceOnly:       o.ReduceOnly,
+		TriggerPrice:     o.TriggerPrice,
+		OrderExpiry:      o.OrderExpiry,
+	}
+}
+
+type CreateOrder struct {
+	TxOpts
+	Order
+}
+
+func (r *CreateOrder) TxType() uint8 { return txtypes.TxTypeL2CreateOrder }
+
+func (r *CreateOrder) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	return types.ConstructCreateOrderTx(keyManager, chainId, r.Order.request(), ops)
+}
+
+type CancelOrder struct {
+	TxOpts
+	MarketIndex uint8 `param:"marketIndex"`
+	OrderIndex  int64 `param:"orderIndex,min=0"`
+}
+
+func (r *CancelOrder) TxType() uint8 { return txtypes.TxTypeL2CancelOrder }
+
+func (r *CancelOrder) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	cancelReq := &types.CancelOrderTxReq{
+		MarketIndex: r.MarketIndex,
+		Index:       r.OrderIndex,
+	}
+	return types.ConstructL2CancelOrderTx(keyManager, chainId, cancelReq, ops)
+}
+
+type ModifyOrder struct {
+	TxOpts
+	MarketIndex  uint8  `param:"marketIndex"`
+	OrderIndex   int64  `param:"orderIndex,min=0"`
+	BaseAmount   int64  `param:"baseAmount,min=0"`
+	Price        uint32 `param:"price"`
+	TriggerPrice uint32 `param:"triggerPrice,optional"`
+}
+
+func (r *ModifyOrder) TxType() uint8 { return txtypes.TxTypeL2ModifyOrder }
+
+func (r *ModifyOrder) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	modifyReq := &types.ModifyOrderTxReq{
+		MarketIndex:  r.MarketIndex,
+		Index:        r.OrderIndex,
+		BaseAmount:   r.BaseAmount,
+		Price:        r.Price,
+		TriggerPrice: r.TriggerPrice,
+	}
+	return types.ConstructL2ModifyOrderTx(keyManager, chainId, modifyReq, ops)
+}
+
+type CancelAllOrders struct {
+	TxOpts
+	TimeInForce uint8 `param:"timeInForce,enum=CANCEL_ALL_TIF"`
+	Time        int64 `param:"time,optional,min=0"`
+}
+
+func (r *CancelAllOrders) TxType() uint8 { return txtypes.TxTypeL2CancelAllOrders }
+
+func (r *CancelAllOrders) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	cancelAllReq := &types.CancelAllOrdersTxReq{
+		TimeInForce: r.TimeInForce,
+		Time:        r.Time,
+	}
+	return types.ConstructL2CancelAllOrdersTx(keyManager, chainId, cancelAllReq, ops)
+}
+
+type CreateGroupedOrders struct {
+	TxOpts
+	GroupingType uint8   `param:"groupingType,enum=GROUPING_TYPES"`
+	Orders       []Order `param:"orders,minItems=1"`
+}
+
+func (r *CreateGroupedOrders) TxType() uint8 { return txtypes.TxTypeL2CreateGroupedOrders }
+
+func (r *CreateGroupedOrders) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	orders := make([]*types.CreateOrderTxReq, len(r.Orders))
+	for i := range r.Orders {
+		orders[i] = r.Orders[i].request()
+	}
+
+	groupedReq := &types.CreateGroupedOrdersTxReq{
+		GroupingType: r.GroupingType,
+		Orders:       orders,
+	}
+	return types.ConstructL2CreateGroupedOrdersTx(keyManager, chainId, groupedReq, ops)
+}
+
+type UpdateLeverage struct {
+	TxOpts
+	MarketIndex           uint8  `param:"marketIndex"`
+	InitialMarginFraction uint16 `param:"initialMarginFraction,min=1"`
+	MarginMode            uint8  `param:"marginMode,enum=MARGIN_MODES"`
+}
+
+func (r *UpdateLeverage) TxType() uint8 { return txtypes.TxTypeL2UpdateLeverage }
+
+func (r *UpdateLeverage) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	leverageReq := &types.UpdateLeverageTxReq{
+		MarketIndex:           r.MarketIndex,
+		InitialMarginFraction: r.InitialMarginFraction,
+		MarginMode:            r.MarginMode,
+	}
+	return types.ConstructUpdateLeverageTx(keyManager, chainId, leverageReq, ops)
+}
+
+type UpdateMargin struct {
+	TxOpts
+	MarketIndex uint8 `param:"marketIndex"`
+	USDCAmount  int64 `param:"usdcAmount,min=1"`
+	Direction   uint8 `param:"direction,enum=MARGIN_DIRECTION"`
+}
+
+func (r *UpdateMargin) TxType() uint8 { return txtypes.TxTypeL2UpdateMargin }
+
+func (r *UpdateMargin) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	marginReq := &types.UpdateMarginTxReq{
+		MarketIndex: r.MarketIndex,
+		USDCAmount:  r.USDCAmount,
+		Direction:   r.Direction,
+	}
+	return types.ConstructUpdateMarginTx(keyManager, chainId, marginReq, ops)
+}
+
+type Withdraw struct {
+	TxOpts
+	USDCAmount uint64 `param:"usdcAmount,min=1"`
+}
+
+func (r *Withdraw) TxType() uint8 { return txtypes.TxTypeL2Withdraw }
+
+func (r *Withdraw) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	withdrawReq := &types.WithdrawTxReq{
+		USDCAmount: r.USDCAmount,
+	}
+	return types.ConstructWithdrawTx(keyManager, chainId, withdrawReq, ops)
+}
+
+type Transfer struct {
+	TxOpts
+	ToAccountIndex int64 `param:"toAccountIndex,min=0"`
+	USDCAmount     int64 `param:"usdcAmount,min=1"`
+	Fee            int64 `param:"fee,optional,min=0"`
+	// Memo is zero-padded to 32 bytes
+	Memo string `param:"memo,optional,size=32"`
+}
+
+func (r *Transfer) TxType() uint8 { return txtypes.TxTypeL2Transfer }
+
+func (r *Transfer) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	var memoBytes [32]byte
+	copy(memoBytes[:], r.Memo)
+
+	transferReq := &types.TransferTxReq{
+		ToAccountIndex: r.ToAccountIndex,
+		USDCAmount:     r.USDCAmount,
+		Fee:            r.Fee,
+		Memo:           memoBytes,
+	}
+	return types.ConstructTransferTx(keyManager, chainId, transferReq, ops)
+}
+
+type CreateSubAccount struct {
+	TxOpts
+}
+
+func (r *CreateSubAccount) TxType() uint8 { return txtypes.TxTypeL2CreateSubAccount }
+
+func (r *CreateSubAccount) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	return types.ConstructCreateSubAccountTx(keyManager, chainId, ops)
+}
+
+// ChangePubKey registers NewPubKey for the signer's API key index. The
+// account owner must also sign it on L1: pass at most one of L1Signature (a
+// personal_sign signature made elsewhere) or L1PrivateKey to attach it
+// here, or neither and use AttachL1 later.
+type ChangePubKey struct {
+	TxOpts
+	NewPubKey   []byte `param:"newPubKey,size=40"`
+	L1Signature []byte `param:"l1Signature,optional,size=65"`
+	// L1PrivateKey is wiped once the tx is signed
+	L1PrivateKey []byte `param:"l1PrivateKey,optional,size=32"`
+}
+
+func (r *ChangePubKey) TxType() uint8 { return txtypes.TxTypeL2ChangePubKey }
+
+func (r *ChangePubKey) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	defer func() {
+		for i := range r.L1PrivateKey {
+			r.L1PrivateKey[i] = 0
+		}
+	}()
+
+	var pubKey [40]byte
+	copy(pubKey[:], r.NewPubKey)
+
+	changePubKeyReq := &types.ChangePubKeyReq{
+		PubKey: pubKey,
+	}
+	tx, err := types.ConstructChangePubKeyTx(keyManager, chainId, changePubKeyReq, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optionally add the owner's L1 signature so the tx can be submitted as is
+	if _, err := AttachL1(tx, r.L1Signature, r.L1PrivateKey); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+type CreatePublicPool struct {
+	TxOpts
+	OperatorFee          int64 `param:"operatorFee,min=0"`
+	InitialTotalShares   int64 `param:"initialTotalShares,min=0"`
+	MinOperatorShareRate int64 `param:"minOperatorShareRate,min=0"`
+}
+
+func (r *CreatePublicPool) TxType() uint8 { return txtypes.TxTypeL2CreatePublicPool }
+
+func (r *CreatePublicPool) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	poolReq := &types.CreatePublicPoolTxReq{
+		OperatorFee:          r.OperatorFee,
+		InitialTotalShares:   r.InitialTotalShares,
+		MinOperatorShareRate: r.MinOperatorShareRate,
+	}
+	return types.ConstructCreatePublicPoolTx(keyManager, chainId, poolReq, ops)
+}
+
+type UpdatePublicPool struct {
+	TxOpts
+	PublicPoolIndex      int64 `param:"publicPoolIndex,min=0"`
+	Status               uint8 `param:"status,enum=POOL_STATUS"`
+	OperatorFee          int64 `param:"operatorFee,min=0"`
+	MinOperatorShareRate int64 `param:"minOperatorShareRate,min=0"`
+}
+
+func (r *UpdatePublicPool) TxType() uint8 { return txtypes.TxTypeL2UpdatePublicPool }
+
+func (r *UpdatePublicPool) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	updatePoolReq := &types.UpdatePublicPoolTxReq{
+		PublicPoolIndex:      r.PublicPoolIndex,
+		Status:               r.Status,
+		OperatorFee:          r.OperatorFee,
+		MinOperatorShareRate: r.MinOperatorShareRate,
+	}
+	return types.ConstructUpdatePublicPoolTx(keyManager, chainId, updatePoolReq, ops)
+}
+
+type MintShares struct {
+	TxOpts
+	PublicPoolIndex int64 `param:"publicPoolIndex,min=0"`
+	ShareAmount     int64 `param:"shareAmount,min=1"`
+}
+
+func (r *MintShares) TxType() uint8 { return txtypes.TxTypeL2MintShares }
+
+func (r *MintShares) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	mintReq := &types.MintSharesTxReq{
+		PublicPoolIndex: r.PublicPoolIndex,
+		ShareAmount:     r.ShareAmount,
+	}
+	return types.ConstructMintSharesTx(keyManager, chainId, mintReq, ops)
+}
+
+type BurnShares struct {
+	TxOpts
+	PublicPoolIndex int64 `param:"publicPoolIndex,min=0"`
+	ShareAmount     int64 `param:"shareAmount,min=1"`
+}
+
+func (r *BurnShares) TxType() uint8 { return txtypes.TxTypeL2BurnShares }
+
+func (r *BurnShares) build(keyManager signer.KeyManager, chainId uint32, ops *types.TransactOpts) (Tx, error) {
+	burnReq := &types.BurnSharesTxReq{
+		PublicPoolIndex: r.PublicPoolIndex,
+		ShareAmount:     r.ShareAmount,
+	}
+	return types.ConstructBurnSharesTx(keyManager, chainId, burnReq, ops)
+}
+
+// AuthToken requests an API auth token valid for ExpiryHours
+type AuthToken struct {
+	ExpiryHours int64 `param:"expiryHours,optional,default=8,min=1"`
+}
+
+// Account identifies the account and API key a Signer signs for
+type Account struct {
+	ChainId      uint32 `param:"chainId"`
+	AccountIndex int64  `param:"accountIndex,min=0"`
+	ApiKeyIndex  uint8  `param:"apiKeyIndex"`
+}
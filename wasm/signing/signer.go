@@ -0,0 +1,183 @@
+// Package signing builds and signs Lighter L2 transactions from plain Go
+// requests. lighter.wasm is a thin adapter over it, so Go services and the
+// browser apply the same validation, defaults and expiry policy and
+// produce the same txs.
+package signing
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/elliottech/lighter-go/types"
+	"lighter-wasm/errcodes"
+)
+
+// Signer signs txs for one API key of one account. Copies of a Signer
+// share its key, so zeroizing one disables all of them.
+type Signer struct {
+	Account
+	keyManager *keyManager
+	privateKey []byte
+}
+
+// NewSigner takes ownership of privateKey; Zeroize wipes it, and so does
+// NewSigner itself when it fails
+func NewSigner(privateKey []byte, account Account) (_ *Signer, err error) {
+	defer func() {
+		if err != nil {
+			for i := range privateKey {
+				privateKey[i] = 0
+			}
+		}
+	}()
+
+	if err = Check(&account); err != nil {
+		return nil, err
+	}
+
+	keyManager, err := newKeyManager(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		Account:    account,
+		keyManager: keyManager,
+		privateKey: privateKey,
+	}, nil
+}
+
+// Zeroize wipes the key bytes and the key manager's scalar. A signature
+// already in flight completes first; later ones fail with INVALID_KEY.
+func (s *Signer) Zeroize() {
+	for i := range s.privateKey {
+		s.privateKey[i] = 0
+	}
+	s.privateKey = nil
+	s.keyManager.zeroize()
+}
+
+// PublicKey returns the public key of the signer's API key
+func (s *Signer) PublicKey() []byte {
+	pk := s.keyManager.PubKeyBytes()
+	return pk[:]
+}
+
+// Result describes a signed tx the way sendTx and the WS account_tx
+// channel refer to it, so callers can track it before submitting.
+type Result struct {
+	Tx           Tx
+	TxType       uint8
+	TxInfo       string
+	TxHash       string
+	Nonce        int64
+	ExpiredAt    int64
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	// L1Message is what the account owner personal_signs for AttachL1;
+	// empty unless the tx implements L1SignedTx
+	L1Message string
+}
+
+// Sign validates req and signs it with the current time
+func (s *Signer) Sign(req TxRequest) (*Result, error) {
+	return s.SignAt(req, time.Now())
+}
+
+// SignAt validates req and signs it as of now, which anchors the expiry
+// policy
+func (s *Signer) SignAt(req TxRequest, now time.Time) (*Result, error) {
+	if err := Check(req); err != nil {
+		return nil, err
+	}
+
+	opts := req.opts()
+	expiredAt, err := ResolveExpiredAt(opts.ExpiredAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIndex := s.AccountIndex
+	apiKeyIndex := s.ApiKeyIndex
+	nonce := opts.Nonce
+	ops := &types.TransactOpts{
+		FromAccountIndex: &accountIndex,
+		ApiKeyIndex:      &apiKeyIndex,
+		Nonce:            &nonce,
+		ExpiredAt:        expiredAt,
+	}
+
+	tx, err := req.build(s.keyManager, s.ChainId, ops)
+	if err != nil {
+		return nil, Errorf(errcodes.SignFailed, "", err, "Failed to sign %s", kinds[req.TxType()].Action)
+	}
+
+	txJSON, err := json.Marshal(tx)
+	if err != nil {
+		return nil, Errorf(errcodes.MarshalFailed, "", err, "Failed to marshal JSON")
+	}
+
+	result := &Result{
+		Tx:           tx,
+		TxType:       tx.GetTxType(),
+		TxInfo:       string(txJSON),
+		TxHash:       tx.GetTxHash(),
+		Nonce:        nonce,
+		ExpiredAt:    expiredAt,
+		AccountIndex: accountIndex,
+		ApiKeyIndex:  apiKeyIndex,
+	}
+	if l1, ok := tx.(L1SignedTx); ok {
+		result.L1Message = l1.GetL1SignatureBody()
+	}
+	return result, nil
+}
+
+// SignBatch signs reqs with consecutive nonces starting at startNonce,
+// overwriting their Nonce. expiredAt applies to requests that leave
+// ExpiredAt at 0. Nothing is returned unless every request signs, so a
+// rejected batch never leaves a gap in the nonce sequence; the failing
+// entry is reported as a *BatchError.
+func (s *Signer) SignBatch(reqs []TxRequest, startNonce, expiredAt int64, now time.Time) ([]*Result, error) {
+	if len(reqs) == 0 {
+		return nil, &ParamError{ReasonRange, "txs", "expected at least 1 items, got 0"}
+	}
+
+	results := make([]*Result, len(reqs))
+	for i, req := range reqs {
+		opts := req.opts()
+		opts.Nonce = startNonce + int64(i)
+		if opts.ExpiredAt == 0 {
+			opts.ExpiredAt = expiredAt
+		}
+
+		result, err := s.SignAt(req, now)
+		if err != nil {
+			return nil, &BatchError{i, err}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// AuthToken creates an API auth token valid for req.ExpiryHours from now
+func (s *Signer) AuthToken(req AuthToken, now time.Time) (string, error) {
+	if err := Check(&req); err != nil {
+		return "", err
+	}
+
+	accountIndex := s.AccountIndex
+	apiKeyIndex := s.ApiKeyIndex
+	deadline := now.Add(time.Duration(req.ExpiryHours) * time.Hour)
+
+	ops := &types.TransactOpts{
+		FromAccountIndex: &accountIndex,
+		ApiKeyIndex:      &apiKeyIndex,
+	}
+
+	authToken, err := types.ConstructAuthToken(s.keyManager, deadline, ops)
+	if err != nil {
+		return "", Errorf(errcodes.SignFailed, "", err, "Failed to create auth token")
+	}
+	return authToken, nil
+}
@@ -0,0 +1,98 @@
+package signing
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+	g "github.com/elliottech/poseidon_crypto/field/goldilocks"
+	poseidon2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"lighter-wasm/errcodes"
+)
+
+// ParseTx unmarshals a tx_info blob into its lighter-go struct and returns
+// the signature it carries
+func ParseTx(txType uint8, txInfo string) (txtypes.TxInfo, []byte, error) {
+	kind, ok := kinds[txType]
+	if !ok {
+		return nil, nil, Errorf(errcodes.InvalidParam, "txType", nil, "Unsupported tx type: %d", txType)
+	}
+
+	tx := kind.NewTx()
+	if err := json.Unmarshal([]byte(txInfo), tx); err != nil {
+		return nil, nil, fmt.Errorf("Invalid %s tx info: %v", kind.Name, err)
+	}
+
+	var sig struct{ Sig []byte }
+	if err := json.Unmarshal([]byte(txInfo), &sig); err != nil {
+		return nil, nil, fmt.Errorf("Invalid %s signature: %v", kind.Name, err)
+	}
+	return tx, sig.Sig, nil
+}
+
+// VerifyTx recomputes the Poseidon hash of tx and checks sig against
+// pubKey. It returns the recomputed hash even if the check fails.
+func VerifyTx(tx txtypes.TxInfo, sig []byte, chainId uint32, pubKey []byte) ([]byte, error) {
+	msgHash, err := tx.Hash(chainId)
+	if err != nil {
+		return nil, Errorf(errcodes.SignFailed, "", err, "Failed to hash tx")
+	}
+	return msgHash, schnorr.Validate(pubKey, msgHash, sig)
+}
+
+// AuthTokenClaims is what an auth token from Signer.AuthToken asserts. The
+// token is deadline:accountIndex:apiKeyIndex:signature, with the signature
+// over the Poseidon2 hash of the text before it.
+type AuthTokenClaims struct {
+	Deadline     time.Time
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	message      string
+	sig          []byte
+}
+
+// ParseAuthToken splits an auth token into its claims without checking it
+func ParseAuthToken(token string) (*AuthTokenClaims, error) {
+	i := strings.LastIndex(token, ":")
+	parts := strings.Split(token[:max(i, 0)], ":")
+	if i < 0 || len(parts) != 3 {
+		return nil, fmt.Errorf("Invalid auth token: expected deadline:accountIndex:apiKeyIndex:signature")
+	}
+	deadline, err1 := strconv.ParseInt(parts[0], 10, 64)
+	accountIndex, err2 := strconv.ParseInt(parts[1], 10, 64)
+	apiKeyIndex, err3 := strconv.ParseUint(parts[2], 10, 8)
+	sig, err4 := hex.DecodeString(token[i+1:])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return nil, fmt.Errorf("Invalid auth token: malformed field")
+	}
+
+	return &AuthTokenClaims{
+		Deadline:     time.Unix(deadline, 0),
+		AccountIndex: accountIndex,
+		ApiKeyIndex:  uint8(apiKeyIndex),
+		message:      token[:i],
+		sig:          sig,
+	}, nil
+}
+
+// Verify checks the token's signature against pubKey, the public key of
+// its API key, and that it has not expired at now
+func (c *AuthTokenClaims) Verify(pubKey []byte, now time.Time) error {
+	if !now.Before(c.Deadline) {
+		return fmt.Errorf("Auth token expired at %s", c.Deadline.UTC().Format(time.RFC3339))
+	}
+	elems, err := g.ArrayFromCanonicalLittleEndianBytes([]byte(c.message))
+	if err != nil {
+		return fmt.Errorf("Invalid auth token: %v", err)
+	}
+	msgHash := poseidon2.HashToQuinticExtension(elems).ToLittleEndianBytes()
+	if err := schnorr.Validate(pubKey, msgHash, c.sig); err != nil {
+		return fmt.Errorf("Invalid auth token signature: %v", err)
+	}
+	return nil
+}
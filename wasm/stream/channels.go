@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"fmt"
+)
+
+// Channels, mirroring WS_CHANNELS in sdk/core/constants.js. The legacy
+// channels there have no typed helper; use Subscribe directly.
+const (
+	ChannelOrderBook           = "order_book"            // order_book/{MARKET_INDEX}
+	ChannelTrade               = "trade"                 // trade/{MARKET_INDEX}
+	ChannelMarketStats         = "market_stats"          // market_stats/{MARKET_INDEX} or market_stats/all
+	ChannelHeight              = "height"                // height
+	ChannelAccountAll          = "account_all"           // account_all/{ACCOUNT_ID}
+	ChannelAccountMarket       = "account_market"        // account_market/{MARKET_ID}/{ACCOUNT_ID}
+	ChannelAccountAllOrders    = "account_all_orders"    // account_all_orders/{ACCOUNT_ID}
+	ChannelAccountOrders       = "account_orders"        // account_orders/{MARKET_INDEX}/{ACCOUNT_ID}
+	ChannelAccountAllTrades    = "account_all_trades"    // account_all_trades/{ACCOUNT_ID}
+	ChannelAccountAllPositions = "account_all_positions" // account_all_positions/{ACCOUNT_ID}
+	ChannelAccountTx           = "account_tx"            // account_tx/{ACCOUNT_ID}
+	ChannelUserStats           = "user_stats"            // user_stats/{ACCOUNT_ID}
+	ChannelNotification        = "notification"          // notification/{ACCOUNT_ID}
+	ChannelPoolData            = "pool_data"             // pool_data/{ACCOUNT_ID}
+	ChannelPoolInfo            = "pool_info"             // pool_info/{ACCOUNT_ID}
+)
+
+// subscribeTyped subscribes channel and decodes each message into the value
+// returned by newValue before calling fn with it. Decode failures go to
+// OnError.
+func (c *Client) subscribeTyped(channel string, auth bool, newValue func() interface{}, fn func(interface{})) {
+	c.Subscribe(channel, auth, func(m *Message) {
+		v := newValue()
+		if err := m.Decode(v); err != nil {
+			c.report(fmt.Errorf("lighter stream %s: invalid message: %w", m.Channel, err))
+			return
+		}
+		if h, ok := v.(interface{ header() *Header }); ok {
+			h.header().Channel = m.Channel
+		}
+		fn(v)
+	})
+}
+
+// SubscribeOrderBook streams the book deltas of a market
+func (c *Client) SubscribeOrderBook(marketId uint8, fn func(*OrderBookUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelOrderBook, marketId), false,
+		func() interface{} { return &OrderBookUpdate{} },
+		func(v interface{}) { fn(v.(*OrderBookUpdate)) })
+}
+
+// SubscribeTrade streams the trades of a market
+func (c *Client) SubscribeTrade(marketId uint8, fn func(*TradeUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelTrade, marketId), false,
+		func() interface{} { return &TradeUpdate{} },
+		func(v interface{}) { fn(v.(*TradeUpdate)) })
+}
+
+// SubscribeMarketStats streams the statistics of a market
+func (c *Client) SubscribeMarketStats(marketId uint8, fn func(*MarketStatsUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelMarketStats, marketId), false,
+		func() interface{} { return &MarketStatsUpdate{} },
+		func(v interface{}) { fn(v.(*MarketStatsUpdate)) })
+}
+
+// SubscribeAllMarketStats streams the statistics of every market
+func (c *Client) SubscribeAllMarketStats(fn func(*AllMarketStatsUpdate)) {
+	c.subscribeTyped(ChannelMarketStats+"/all", false,
+		func() interface{} { return &AllMarketStatsUpdate{} },
+		func(v interface{}) { fn(v.(*AllMarketStatsUpdate)) })
+}
+
+// SubscribeHeight streams the chain height
+func (c *Client) SubscribeHeight(fn func(*HeightUpdate)) {
+	c.subscribeTyped(ChannelHeight, false,
+		func() interface{} { return &HeightUpdate{} },
+		func(v interface{}) { fn(v.(*HeightUpdate)) })
+}
+
+// SubscribeAccountAll streams the positions, trades and shares of an account
+func (c *Client) SubscribeAccountAll(accountIndex int64, fn func(*AccountAllUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelAccountAll, accountIndex), false,
+		func() interface{} { return &AccountAllUpdate{} },
+		func(v interface{}) { fn(v.(*AccountAllUpdate)) })
+}
+
+// SubscribeAccountMarket streams an account's orders, position and trades
+// in one market
+func (c *Client) SubscribeAccountMarket(marketId uint8, accountIndex int64, fn func(*AccountMarketUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d/%d", ChannelAccountMarket, marketId, accountIndex), true,
+		func() interface{} { return &AccountMarketUpdate{} },
+		func(v interface{}) { fn(v.(*AccountMarketUpdate)) })
+}
+
+// SubscribeAccountAllOrders streams an account's orders in every market
+func (c *Client) SubscribeAccountAllOrders(accountIndex int64, fn func(*AccountOrdersUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelAccountAllOrders, accountIndex), true,
+		func() interface{} { return &AccountOrdersUpdate{} },
+		func(v interface{}) { fn(v.(*AccountOrdersUpdate)) })
+}
+
+// SubscribeAccountOrders streams an account's orders in one market
+func (c *Client) SubscribeAccountOrders(marketId uint8, accountIndex int64, fn func(*AccountOrdersUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d/%d", ChannelAccountOrders, marketId, accountIndex), true,
+		func() interface{} { return &AccountOrdersUpdate{} },
+		func(v interface{}) { fn(v.(*AccountOrdersUpdate)) })
+}
+
+// SubscribeAccountAllTrades streams an account's trades in every market
+func (c *Client) SubscribeAccountAllTrades(accountIndex int64, fn func(*AccountTradesUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelAccountAllTrades, accountIndex), true,
+		func() interface{} { return &AccountTradesUpdate{} },
+		func(v interface{}) { fn(v.(*AccountTradesUpdate)) })
+}
+
+// SubscribeAccountAllPositions streams an account's positions
+func (c *Client) SubscribeAccountAllPositions(accountIndex int64, fn func(*AccountPositionsUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelAccountAllPositions, accountIndex), true,
+		func() interface{} { return &AccountPositionsUpdate{} },
+		func(v interface{}) { fn(v.(*AccountPositionsUpdate)) })
+}
+
+// SubscribeAccountTx streams the txs of an account, e.g. to confirm the
+// txHash returned when signing
+func (c *Client) SubscribeAccountTx(accountIndex int64, fn func(*AccountTxUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelAccountTx, accountIndex), true,
+		func() interface{} { return &AccountTxUpdate{} },
+		func(v interface{}) { fn(v.(*AccountTxUpdate)) })
+}
+
+// SubscribeUserStats streams an account's balance and margin statistics
+func (c *Client) SubscribeUserStats(accountIndex int64, fn func(*UserStatsUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelUserStats, accountIndex), false,
+		func() interface{} { return &UserStatsUpdate{} },
+		func(v interface{}) { fn(v.(*UserStatsUpdate)) })
+}
+
+// SubscribeNotification streams an account's notifications
+func (c *Client) SubscribeNotification(accountIndex int64, fn func(*NotificationUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelNotification, accountIndex), true,
+		func() interface{} { return &NotificationUpdate{} },
+		func(v interface{}) { fn(v.(*NotificationUpdate)) })
+}
+
+// SubscribePoolData streams the activity of a public pool account
+func (c *Client) SubscribePoolData(accountIndex int64, fn func(*PoolDataUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelPoolData, accountIndex), true,
+		func() interface{} { return &PoolDataUpdate{} },
+		func(v interface{}) { fn(v.(*PoolDataUpdate)) })
+}
+
+// SubscribePoolInfo streams the parameters and returns of a public pool
+func (c *Client) SubscribePoolInfo(accountIndex int64, fn func(*PoolInfoUpdate)) {
+	c.subscribeTyped(fmt.Sprintf("%s/%d", ChannelPoolInfo, accountIndex), true,
+		func() interface{} { return &PoolInfoUpdate{} },
+		func(v interface{}) { fn(v.(*PoolInfoUpdate)) })
+}
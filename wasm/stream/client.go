@@ -0,0 +1,456 @@
+// Package stream is a WebSocket client for the Lighter stream API, the Go
+// counterpart of sdk/core/lighter-ws.js. It reconnects with exponential
+// backoff, resubscribes every channel after a reconnect, refreshes the
+// auth token of authenticated channels on each connect and drops
+// connections that stop answering pings.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Timing mirrors lighter-ws.js
+const (
+	HeartbeatInterval = 30 * time.Second
+	PongTimeout       = 90 * time.Second
+	MinReconnectDelay = time.Second
+	MaxReconnectDelay = 60 * time.Second
+	MaxQueueSize      = 100
+	writeTimeout      = 10 * time.Second
+)
+
+// AuthFunc returns a fresh auth token, e.g. from signing.Signer.AuthToken
+// or types.ConstructAuthToken. It is called on every connect when the
+// client has authenticated subscriptions.
+type AuthFunc func(ctx context.Context) (string, error)
+
+// StaticAuth always returns token
+func StaticAuth(token string) AuthFunc {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// Message is one server message of a subscribed channel. Channel uses
+// slashes, e.g. "order_book/0", whatever separator the server sent.
+type Message struct {
+	Type    string
+	Channel string
+	Raw     json.RawMessage
+}
+
+// Decode unmarshals the whole message into v
+func (m *Message) Decode(v interface{}) error {
+	return json.Unmarshal(m.Raw, v)
+}
+
+// Handler receives the messages of a channel on the client's read
+// goroutine, so it must not block
+type Handler func(*Message)
+
+// ServerError is an error message sent by the server
+type ServerError struct {
+	Channel string
+	Message string
+}
+
+func (e *ServerError) Error() string {
+	if e.Channel == "" {
+		return "lighter stream: " + e.Message
+	}
+	return fmt.Sprintf("lighter stream %s: %s", e.Channel, e.Message)
+}
+
+type subscription struct {
+	auth     bool
+	handlers []Handler
+}
+
+// Client is a reconnecting stream connection. Subscriptions may be added
+// before or while Run is active.
+type Client struct {
+	URL string
+	// Auth provides the token sent with authenticated subscriptions
+	Auth AuthFunc
+	// OnError receives connection, decode and server errors; nil ignores
+	// them
+	OnError func(error)
+	Dialer  *websocket.Dialer
+
+	mu       sync.Mutex
+	conn     *websocket.Conn
+	token    string
+	subs     map[string]*subscription
+	queue    []interface{}
+	lastPong time.Time
+
+	writeMu sync.Mutex
+}
+
+// NewClient returns a client for url, e.g. "wss://mainnet.zklighter.elliot.ai/stream".
+// auth may be nil if no authenticated channel is used.
+func NewClient(url string, auth AuthFunc) *Client {
+	return &Client{
+		URL:  url,
+		Auth: auth,
+		subs: make(map[string]*subscription),
+	}
+}
+
+// Run connects and keeps reconnecting until ctx is done, which is the
+// only way it returns
+func (c *Client) Run(ctx context.Context) error {
+	attempts := 0
+	for {
+		connected, err := c.runConn(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			attempts = 0
+		}
+		c.report(err)
+
+		attempts++
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay(attempts)):
+		}
+	}
+}
+
+// reconnectDelay doubles from MinReconnectDelay up to MaxReconnectDelay
+func reconnectDelay(attempt int) time.Duration {
+	delay := MinReconnectDelay
+	for i := 1; i < attempt && delay < MaxReconnectDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxReconnectDelay {
+		delay = MaxReconnectDelay
+	}
+	return delay
+}
+
+// runConn serves one connection until it drops. connected reports whether
+// the dial succeeded, which resets the backoff.
+func (c *Client) runConn(ctx context.Context) (connected bool, err error) {
+	if c.needsAuth() {
+		if err := c.refreshToken(ctx); err != nil {
+			c.report(fmt.Errorf("lighter stream: auth refresh failed: %w", err))
+		}
+	}
+
+	dialer := c.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	conn, _, err := dialer.DialContext(ctx, c.URL, nil)
+	if err != nil {
+		return false, fmt.Errorf("lighter stream: dial: %w", err)
+	}
+	defer conn.Close()
+
+	c.mu.Lock()
+	c.conn = conn
+	c.lastPong = time.Now()
+	token := c.token
+	subs := c.subscribeMessages()
+	queue := c.queue
+	c.queue = nil
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+	}()
+
+	if token != "" {
+		c.sendControl(map[string]interface{}{"type": "auth", "token": token})
+	}
+	for _, msg := range subs {
+		c.sendControl(msg)
+	}
+	for _, msg := range queue {
+		c.Send(msg)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go c.heartbeat(ctx, conn, done)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return true, fmt.Errorf("lighter stream: read: %w", err)
+		}
+		c.handle(data)
+	}
+}
+
+// heartbeat sends a JSON ping every HeartbeatInterval and closes conn when
+// no pong arrived for PongTimeout or ctx is done, which ends the read loop
+func (c *Client) heartbeat(ctx context.Context, conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			conn.Close()
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			silent := time.Since(c.lastPong)
+			c.mu.Unlock()
+			if silent > PongTimeout {
+				c.report(fmt.Errorf("lighter stream: no pong for %s, reconnecting", silent.Round(time.Second)))
+				conn.Close()
+				return
+			}
+			c.sendControl(map[string]interface{}{"type": "ping"})
+		}
+	}
+}
+
+func (c *Client) touch() {
+	c.mu.Lock()
+	c.lastPong = time.Now()
+	c.mu.Unlock()
+}
+
+func (c *Client) report(err error) {
+	if err != nil && c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+func (c *Client) needsAuth() bool {
+	if c.Auth == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, sub := range c.subs {
+		if sub.auth {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) refreshToken(ctx context.Context) error {
+	token, err := c.Auth(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+	return nil
+}
+
+// Send writes msg as JSON, or queues it while disconnected. The queue
+// keeps the latest MaxQueueSize messages and is flushed on connect.
+func (c *Client) Send(msg interface{}) {
+	c.send(msg, true)
+}
+
+// sendControl writes msg only if connected. It is for auth, subscription
+// and heartbeat messages, which runConn sends again from c.subs on the
+// next connect, so queueing them would send them twice.
+func (c *Client) sendControl(msg interface{}) {
+	c.send(msg, false)
+}
+
+func (c *Client) send(msg interface{}, queue bool) {
+	c.mu.Lock()
+	conn := c.conn
+	if conn == nil {
+		if queue {
+			c.enqueue(msg)
+		}
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	if err := c.write(conn, msg); err != nil {
+		c.report(err)
+		if queue {
+			c.mu.Lock()
+			c.enqueue(msg)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// enqueue must be called with mu held
+func (c *Client) enqueue(msg interface{}) {
+	if len(c.queue) >= MaxQueueSize {
+		c.queue = c.queue[1:]
+	}
+	c.queue = append(c.queue, msg)
+}
+
+func (c *Client) write(conn *websocket.Conn, msg interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := conn.WriteJSON(msg); err != nil {
+		return fmt.Errorf("lighter stream: write: %w", err)
+	}
+	return nil
+}
+
+// Subscribe adds h to channel, e.g. "order_book/0", and subscribes if this
+// is the channel's first handler. auth attaches the token from Auth.
+func (c *Client) Subscribe(channel string, auth bool, h Handler) {
+	c.mu.Lock()
+	if c.subs == nil {
+		c.subs = make(map[string]*subscription)
+	}
+	sub, ok := c.subs[channel]
+	if !ok {
+		sub = &subscription{auth: auth}
+		c.subs[channel] = sub
+	}
+	sub.handlers = append(sub.handlers, h)
+	connected := c.conn != nil
+	token := c.token
+	c.mu.Unlock()
+
+	// Before Run, or while reconnecting, runConn subscribes
+	if ok || !connected {
+		return
+	}
+	if auth && token == "" && c.Auth != nil {
+		if err := c.refreshToken(context.Background()); err != nil {
+			c.report(fmt.Errorf("lighter stream: auth refresh failed: %w", err))
+		}
+		c.mu.Lock()
+		token = c.token
+		c.mu.Unlock()
+	}
+	c.sendControl(subscribeMessage(channel, auth, token))
+}
+
+// Resubscribe unsubscribes and subscribes channel again, keeping its
+// handlers, so the server resends its snapshot. It does nothing while
+// disconnected since the next connect subscribes anyway.
+func (c *Client) Resubscribe(channel string) {
+	c.mu.Lock()
+	sub, ok := c.subs[channel]
+	connected := c.conn != nil
+	token := c.token
+	c.mu.Unlock()
+
+	if ok && connected {
+		c.sendControl(map[string]interface{}{"type": "unsubscribe", "channel": channel})
+		c.sendControl(subscribeMessage(channel, sub.auth, token))
+	}
+}
+
+// Unsubscribe drops every handler of channel
+func (c *Client) Unsubscribe(channel string) {
+	c.mu.Lock()
+	_, ok := c.subs[channel]
+	delete(c.subs, channel)
+	connected := c.conn != nil
+	c.mu.Unlock()
+
+	if ok && connected {
+		c.sendControl(map[string]interface{}{"type": "unsubscribe", "channel": channel})
+	}
+}
+
+// subscribeMessages must be called with mu held
+func (c *Client) subscribeMessages() []interface{} {
+	msgs := make([]interface{}, 0, len(c.subs))
+	for channel, sub := range c.subs {
+		msgs = append(msgs, subscribeMessage(channel, sub.auth, c.token))
+	}
+	return msgs
+}
+
+func subscribeMessage(channel string, auth bool, token string) map[string]interface{} {
+	msg := map[string]interface{}{"type": "subscribe", "channel": channel}
+	if auth && token != "" {
+		msg["auth"] = token
+	}
+	return msg
+}
+
+// handle routes one server message
+func (c *Client) handle(data []byte) {
+	var env struct {
+		Type    string          `json:"type"`
+		Channel string          `json:"channel"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &env); err != nil {
+		c.report(fmt.Errorf("lighter stream: invalid message: %w", err))
+		return
+	}
+
+	switch {
+	case env.Type == "ping":
+		c.touch()
+		c.sendControl(map[string]interface{}{"type": "pong"})
+		return
+	case env.Type == "pong":
+		c.touch()
+		return
+	case env.Type == "connected", strings.HasPrefix(env.Type, "auth"):
+		return
+	case len(env.Error) > 0 && string(env.Error) != "null":
+		c.report(&ServerError{normalizeChannel(env.Channel), errorText(env.Error)})
+		return
+	case env.Channel == "":
+		return
+	}
+
+	channel := normalizeChannel(env.Channel)
+	c.mu.Lock()
+	var handlers []Handler
+	if sub, ok := c.subs[channel]; ok {
+		handlers = append(handlers, sub.handlers...)
+	}
+	c.mu.Unlock()
+
+	msg := &Message{env.Type, channel, data}
+	for _, h := range handlers {
+		h(msg)
+	}
+}
+
+// normalizeChannel converts the server's "order_book:0" to "order_book/0"
+func normalizeChannel(channel string) string {
+	return strings.ReplaceAll(channel, ":", "/")
+}
+
+// errorText unwraps an error that is a string or {message}
+func errorText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var obj struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &obj) == nil && obj.Message != "" {
+		return obj.Message
+	}
+	return string(raw)
+}
@@ -0,0 +1,217 @@
+package stream
+
+import (
+	"encoding/json"
+	"strings"
+
+	"lighter-wasm/api"
+)
+
+// Header is common to every channel message. Type is "subscribed/<channel>"
+// for the snapshot sent on subscribe and "update/<channel>" afterwards;
+// Channel is normalized like Message.Channel.
+type Header struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+}
+
+func (h *Header) header() *Header {
+	return h
+}
+
+// IsSnapshot reports whether the message is the initial state sent on
+// subscribe
+func (h *Header) IsSnapshot() bool {
+	return strings.HasPrefix(h.Type, "subscribed/")
+}
+
+// PriceLevel is the total size resting at one price. A Size of "0" in an
+// update removes the level.
+type PriceLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// OrderBookState is the book part of an order_book message. In an update
+// Asks and Bids hold only the changed levels. Each message's BeginNonce
+// equals the previous message's Nonce unless updates were missed.
+type OrderBookState struct {
+	Code       int          `json:"code"`
+	Asks       []PriceLevel `json:"asks"`
+	Bids       []PriceLevel `json:"bids"`
+	Offset     int64        `json:"offset"`
+	Nonce      int64        `json:"nonce"`
+	BeginNonce int64        `json:"begin_nonce"`
+}
+
+// OrderBookUpdate is an order_book message
+type OrderBookUpdate struct {
+	Header
+	Offset    int64          `json:"offset"`
+	Timestamp int64          `json:"timestamp"`
+	OrderBook OrderBookState `json:"order_book"`
+}
+
+// TradeUpdate is a trade message
+type TradeUpdate struct {
+	Header
+	Trades            []api.Trade `json:"trades"`
+	LiquidationTrades []api.Trade `json:"liquidation_trades"`
+}
+
+// MarketStats are the live statistics of one market
+type MarketStats struct {
+	MarketId              uint8   `json:"market_id"`
+	IndexPrice            string  `json:"index_price"`
+	MarkPrice             string  `json:"mark_price"`
+	OpenInterest          string  `json:"open_interest"`
+	LastTradePrice        string  `json:"last_trade_price"`
+	CurrentFundingRate    string  `json:"current_funding_rate"`
+	FundingRate           string  `json:"funding_rate"`
+	FundingTimestamp      int64   `json:"funding_timestamp"`
+	DailyBaseTokenVolume  float64 `json:"daily_base_token_volume"`
+	DailyQuoteTokenVolume float64 `json:"daily_quote_token_volume"`
+	DailyPriceLow         float64 `json:"daily_price_low"`
+	DailyPriceHigh        float64 `json:"daily_price_high"`
+	DailyPriceChange      float64 `json:"daily_price_change"`
+}
+
+// MarketStatsUpdate is a market_stats/{MARKET_INDEX} message
+type MarketStatsUpdate struct {
+	Header
+	MarketStats MarketStats `json:"market_stats"`
+}
+
+// AllMarketStatsUpdate is a market_stats/all message, keyed by market id
+type AllMarketStatsUpdate struct {
+	Header
+	MarketStats map[string]MarketStats `json:"market_stats"`
+}
+
+// HeightUpdate is a height message
+type HeightUpdate struct {
+	Header
+	Height int64 `json:"height"`
+}
+
+// PoolShare is an account's stake in a public pool
+type PoolShare struct {
+	PublicPoolIndex int64  `json:"public_pool_index"`
+	SharesAmount    int64  `json:"shares_amount"`
+	EntryUsdc       string `json:"entry_usdc"`
+}
+
+// AccountAllUpdate is an account_all message. Maps are keyed by market id.
+type AccountAllUpdate struct {
+	Header
+	Account          int64                      `json:"account"`
+	DailyTradesCount int64                      `json:"daily_trades_count"`
+	DailyVolume      float64                    `json:"daily_volume"`
+	Positions        map[string]api.Position    `json:"positions"`
+	Trades           map[string][]api.Trade     `json:"trades"`
+	FundingHistories map[string]json.RawMessage `json:"funding_histories"`
+	Shares           []PoolShare                `json:"shares"`
+}
+
+// AccountMarketUpdate is an account_market message
+type AccountMarketUpdate struct {
+	Header
+	Account        int64           `json:"account"`
+	Orders         []api.Order     `json:"orders"`
+	Position       *api.Position   `json:"position"`
+	Trades         []api.Trade     `json:"trades"`
+	FundingHistory json.RawMessage `json:"funding_history"`
+}
+
+// AccountOrdersUpdate is an account_orders or account_all_orders message.
+// Orders are keyed by market id.
+type AccountOrdersUpdate struct {
+	Header
+	Account int64                  `json:"account"`
+	Nonce   int64                  `json:"nonce"`
+	Orders  map[string][]api.Order `json:"orders"`
+}
+
+// AccountTradesUpdate is an account_all_trades message. Trades are keyed
+// by market id.
+type AccountTradesUpdate struct {
+	Header
+	Trades        map[string][]api.Trade `json:"trades"`
+	TotalVolume   float64                `json:"total_volume"`
+	MonthlyVolume float64                `json:"monthly_volume"`
+	WeeklyVolume  float64                `json:"weekly_volume"`
+	DailyVolume   float64                `json:"daily_volume"`
+}
+
+// AccountPositionsUpdate is an account_all_positions message. Positions
+// are keyed by market id.
+type AccountPositionsUpdate struct {
+	Header
+	Positions map[string]api.Position `json:"positions"`
+	Shares    []PoolShare             `json:"shares"`
+}
+
+// AccountTxUpdate is an account_tx message
+type AccountTxUpdate struct {
+	Header
+	Txs []api.Tx `json:"txs"`
+}
+
+// UserStats are an account's balance and margin figures, as decimal
+// strings
+type UserStats struct {
+	Collateral       string          `json:"collateral"`
+	PortfolioValue   string          `json:"portfolio_value"`
+	Leverage         string          `json:"leverage"`
+	AvailableBalance string          `json:"available_balance"`
+	MarginUsage      string          `json:"margin_usage"`
+	BuyingPower      string          `json:"buying_power"`
+	CrossStats       json.RawMessage `json:"cross_stats"`
+	TotalStats       json.RawMessage `json:"total_stats"`
+}
+
+// UserStatsUpdate is a user_stats message
+type UserStatsUpdate struct {
+	Header
+	Stats UserStats `json:"stats"`
+}
+
+// NotificationUpdate is a notification message. The notification schema
+// varies by kind, so entries are left undecoded.
+type NotificationUpdate struct {
+	Header
+	Notifs []json.RawMessage `json:"notifs"`
+}
+
+// PoolData is the activity of a public pool account, keyed by market id
+type PoolData struct {
+	Trades           map[string][]api.Trade     `json:"trades"`
+	Orders           map[string][]api.Order     `json:"orders"`
+	Positions        map[string]api.Position    `json:"positions"`
+	Shares           []PoolShare                `json:"shares"`
+	FundingHistories map[string]json.RawMessage `json:"funding_histories"`
+}
+
+// PoolDataUpdate is a pool_data message
+type PoolDataUpdate struct {
+	Header
+	PoolData PoolData `json:"pool_data"`
+}
+
+// PoolInfo describes a public pool
+type PoolInfo struct {
+	Status                int             `json:"status"`
+	OperatorFee           string          `json:"operator_fee"`
+	MinOperatorShareRate  string          `json:"min_operator_share_rate"`
+	TotalShares           int64           `json:"total_shares"`
+	OperatorShares        int64           `json:"operator_shares"`
+	AnnualPercentageYield float64         `json:"annual_percentage_yield"`
+	DailyReturns          json.RawMessage `json:"daily_returns"`
+	SharePrices           json.RawMessage `json:"share_prices"`
+}
+
+// PoolInfoUpdate is a pool_info message
+type PoolInfoUpdate struct {
+	Header
+	PoolInfo PoolInfo `json:"pool_info"`
+}
@@ -0,0 +1,84 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/elliottech/lighter-go/types/txtypes"
+)
+
+// throwingCall wraps a Go function returning {ok, value} or {ok, error}
+// in a JS function that returns the value or throws the error. Go cannot
+// throw into JavaScript itself, so the throw happens in this trampoline.
+var throwingCall = js.Global().Get("Function").New("fn", `
+	return function() {
+		const r = fn.apply(this, arguments);
+		if (!r.ok) throw r.error;
+		return r.value;
+	};
+`)
+
+// syncFunc exposes fn as a synchronous JS function. It runs on the calling
+// goroutine, so it skips the promise and goroutine handoff of newPromise;
+// errors and panics are thrown with the same values newPromise rejects with.
+func syncFunc(fn func(args []js.Value) (interface{}, error)) js.Value {
+	call := js.FuncOf(func(this js.Value, args []js.Value) (result interface{}) {
+		defer func() {
+			if r := recover(); r != nil {
+				result = map[string]interface{}{
+					"ok":    false,
+					"error": errorValue(panicError(r)),
+				}
+			}
+		}()
+
+		value, err := fn(args)
+		if err != nil {
+			return map[string]interface{}{
+				"ok":    false,
+				"error": errorValue(err),
+			}
+		}
+		return map[string]interface{}{
+			"ok":    true,
+			"value": value,
+		}
+	})
+	return throwingCall.Invoke(call)
+}
+
+// syncSigner is the synchronous form of the sign* export for txType
+func syncSigner(txType uint8) js.Value {
+	return syncFunc(func(args []js.Value) (interface{}, error) {
+		return signArgs(txType, args)
+	})
+}
+
+// syncExports are the LighterWASM.sync functions: the signing exports
+// with the same arguments, returning the result or throwing instead of
+// returning a promise. Signing is pure computation, so nothing here waits
+// on the JS event loop.
+func syncExports() map[string]interface{} {
+	return map[string]interface{}{
+		"signTx":                  syncFunc(signTxArgs),
+		"signBatch":               syncFunc(signBatchArgs),
+		"signCreateOrder":         syncSigner(txtypes.TxTypeL2CreateOrder),
+		"signCancelOrder":         syncSigner(txtypes.TxTypeL2CancelOrder),
+		"signModifyOrder":         syncSigner(txtypes.TxTypeL2ModifyOrder),
+		"signCancelAllOrders":     syncSigner(txtypes.TxTypeL2CancelAllOrders),
+		"signCreateGroupedOrders": syncSigner(txtypes.TxTypeL2CreateGroupedOrders),
+		"signUpdateLeverage":      syncSigner(txtypes.TxTypeL2UpdateLeverage),
+		"signUpdateMargin":        syncSigner(txtypes.TxTypeL2UpdateMargin),
+		"signWithdraw":            syncSigner(txtypes.TxTypeL2Withdraw),
+		"signTransfer":            syncSigner(txtypes.TxTypeL2Transfer),
+		"signCreateSubAccount":    syncSigner(txtypes.TxTypeL2CreateSubAccount),
+		"signChangePubKey":        syncSigner(txtypes.TxTypeL2ChangePubKey),
+		"signCreatePublicPool":    syncSigner(txtypes.TxTypeL2CreatePublicPool),
+		"signUpdatePublicPool":    syncSigner(txtypes.TxTypeL2UpdatePublicPool),
+		"signMintShares":          syncSigner(txtypes.TxTypeL2MintShares),
+		"signBurnShares":          syncSigner(txtypes.TxTypeL2BurnShares),
+		"createAuthToken":         syncFunc(createAuthTokenArgs),
+	}
+}